@@ -0,0 +1,33 @@
+// Package clipboard reads text from the system clipboard
+package clipboard
+
+import (
+	"errors"
+
+	exec "golang.org/x/sys/execabs"
+)
+
+// ErrUnsupported is returned when the clipboard can't be accessed on this
+// platform
+var ErrUnsupported = errors.New("reading the clipboard is not supported on this platform")
+
+// execCommand runs the paste program, it is swapped in tests
+var execCommand = exec.Command
+
+// runPaste runs the first available paste program and returns its output
+func runPaste(commands [][]string) (string, error) {
+	for _, command := range commands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+
+		output, err := execCommand(command[0], command[1:]...).Output()
+		if err != nil {
+			return "", err
+		}
+
+		return string(output), nil
+	}
+
+	return "", ErrUnsupported
+}
@@ -0,0 +1,9 @@
+//go:build darwin
+// +build darwin
+
+package clipboard
+
+// Read returns the text content of the system clipboard
+func Read() (string, error) {
+	return runPaste([][]string{{"pbpaste"}})
+}
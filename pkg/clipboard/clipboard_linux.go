@@ -0,0 +1,14 @@
+//go:build linux
+// +build linux
+
+package clipboard
+
+// Read returns the text content of the system clipboard, using wl-paste on
+// Wayland and xclip or xsel on X11
+func Read() (string, error) {
+	return runPaste([][]string{
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-out"},
+		{"xsel", "--clipboard", "--output"},
+	})
+}
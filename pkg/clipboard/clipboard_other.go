@@ -0,0 +1,9 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+package clipboard
+
+// Read always fails on platforms without clipboard support
+func Read() (string, error) {
+	return "", ErrUnsupported
+}
@@ -0,0 +1,74 @@
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	exec "golang.org/x/sys/execabs"
+)
+
+// TestPasteHelper stands in for a paste program when run by fakePaste
+func TestPasteHelper(t *testing.T) {
+	if os.Getenv("BE_TestPasteHelper") != "1" {
+		return
+	}
+
+	fmt.Print(os.Getenv("PASTE_OUTPUT"))
+
+	if os.Getenv("PASTE_FAIL") == "1" {
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+// fakePaste makes runPaste run TestPasteHelper, which prints output, instead
+// of the paste program, and returns the paste commands to run
+func fakePaste(t *testing.T, output string, fail bool) [][]string {
+	t.Helper()
+
+	t.Cleanup(func() { execCommand = exec.Command })
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=TestPasteHelper")
+		cmd.Env = append(os.Environ(), "BE_TestPasteHelper=1", "PASTE_OUTPUT="+output)
+		if fail {
+			cmd.Env = append(cmd.Env, "PASTE_FAIL=1")
+		}
+
+		return cmd
+	}
+
+	// the test binary itself is always available
+	return [][]string{{"stripe-cli-missing-paste"}, {os.Args[0], "--out"}}
+}
+
+func TestRunPaste(t *testing.T) {
+	output, err := runPaste(fakePaste(t, "sk_test_1234567890abcd", false))
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", output)
+}
+
+func TestRunPasteKeepsTrailingNewline(t *testing.T) {
+	// callers trim the output, which some paste programs end with a newline
+	output, err := runPaste(fakePaste(t, "sk_test_1234567890abcd\n", false))
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd\n", output)
+}
+
+func TestRunPasteEmpty(t *testing.T) {
+	output, err := runPaste(fakePaste(t, "", false))
+	require.NoError(t, err)
+	require.Empty(t, output)
+}
+
+func TestRunPasteFails(t *testing.T) {
+	_, err := runPaste(fakePaste(t, "", true))
+	require.Error(t, err)
+}
+
+func TestRunPasteMissingTool(t *testing.T) {
+	_, err := runPaste([][]string{{"stripe-cli-missing-paste"}})
+	require.ErrorIs(t, err, ErrUnsupported)
+}
@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+// Read returns the text content of the system clipboard
+func Read() (string, error) {
+	return runPaste([][]string{{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}})
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 
+	configcmd "github.com/stripe/stripe-cli/pkg/cmd/config"
 	"github.com/stripe/stripe-cli/pkg/config"
 )
 
@@ -38,6 +39,19 @@ you need more granular control over the configuration.`,
 
 	cc.cmd.Flags().SetInterspersed(false) // allow args to happen after flags to enable 2 arguments to --set
 
+	cc.cmd.AddCommand(configcmd.NewRenameProfileCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewUseCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewSetAPIVersionCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewValidateKeyCmd().Cmd)
+	cc.cmd.AddCommand(configcmd.NewValidateCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewDoctorCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewDumpCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewTestKeyringCmd().Cmd)
+	cc.cmd.AddCommand(configcmd.NewShowKeyringItemsCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewBackupCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewRestoreCmd(cc.config).Cmd)
+	cc.cmd.AddCommand(configcmd.NewPermissionsFixCmd(cc.config).Cmd)
+
 	return cc
 }
 
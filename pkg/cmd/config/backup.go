@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// BackupCmd is the struct used for configuring the backup command
+type BackupCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	to string
+}
+
+// NewBackupCmd creates a new command for backing up the profiles file
+func NewBackupCmd(cfg *config.Config) *BackupCmd {
+	bc := &BackupCmd{
+		cfg: cfg,
+	}
+
+	bc.Cmd = &cobra.Command{
+		Use:   "backup",
+		Args:  validators.NoArgs,
+		Short: "Back up your config file",
+		Long: `Copy your config file to a backup file that only you can read. Keys stored in
+the system keyring are not part of the backup.`,
+		Example: `stripe config backup --to config.backup.toml`,
+		RunE:    bc.runBackupCmd,
+	}
+
+	bc.Cmd.Flags().StringVar(&bc.to, "to", "", "Path of the backup file")
+	bc.Cmd.MarkFlagRequired("to") // #nosec G104
+
+	return bc
+}
+
+func (bc *BackupCmd) runBackupCmd(cmd *cobra.Command, args []string) error {
+	err := bc.cfg.BackupProfilesFile(bc.to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Backed up %s to %s\n", bc.cfg.ProfilesFile, bc.to)
+
+	return nil
+}
+
+// RestoreCmd is the struct used for configuring the restore command
+type RestoreCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	from string
+}
+
+// NewRestoreCmd creates a new command for restoring the profiles file from a
+// backup
+func NewRestoreCmd(cfg *config.Config) *RestoreCmd {
+	rc := &RestoreCmd{
+		cfg: cfg,
+	}
+
+	rc.Cmd = &cobra.Command{
+		Use:   "restore",
+		Args:  validators.NoArgs,
+		Short: "Restore your config file from a backup",
+		Long: `Replace your config file with a backup made with "stripe config backup". The
+backup must only be readable by you and must be a valid config file.`,
+		Example: `stripe config restore --from config.backup.toml`,
+		RunE:    rc.runRestoreCmd,
+	}
+
+	rc.Cmd.Flags().StringVar(&rc.from, "from", "", "Path of the backup file")
+	rc.Cmd.MarkFlagRequired("from") // #nosec G104
+
+	return rc
+}
+
+func (rc *RestoreCmd) runRestoreCmd(cmd *cobra.Command, args []string) error {
+	err := rc.cfg.RestoreProfilesFile(rc.from)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Restored %s from %s\n", rc.cfg.ProfilesFile, rc.from)
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/stripe/stripe-cli/pkg/ansi"
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/login"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// statuses of a doctor check
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// ErrReported is returned by commands whose JSON output already reports the
+// failure, so it only sets a non-zero exit status and isn't printed again
+var ErrReported = errors.New("the failure is reported in the output")
+
+// doctorCheck is the result of a single health check of the configuration
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// DoctorCmd is the struct used for configuring the doctor command
+type DoctorCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	// fetcher defaults to the one `stripe login` uses for the active profile
+	fetcher login.AccountFetcher
+	verify  bool
+	json    bool
+}
+
+// NewDoctorCmd creates a new command for checking the health of the
+// configuration
+func NewDoctorCmd(cfg *config.Config) *DoctorCmd {
+	dc := &DoctorCmd{
+		cfg: cfg,
+	}
+
+	dc.Cmd = &cobra.Command{
+		Use:   "doctor",
+		Args:  validators.NoArgs,
+		Short: "Check the health of your configuration",
+		Long: `Check the health of your configuration: the profiles file, the active
+profile, its API keys and the keyring. Exits with a non-zero status if any
+check fails.`,
+		Example: `stripe config doctor
+  stripe config doctor --verify --json`,
+		RunE: dc.runDoctorCmd,
+	}
+
+	dc.Cmd.Flags().BoolVar(&dc.verify, "verify", false, "Check that the test mode API key is accepted by the Stripe API")
+	dc.Cmd.Flags().BoolVar(&dc.json, "json", false, "Output the checks as JSON")
+
+	return dc
+}
+
+func (dc *DoctorCmd) runDoctorCmd(cmd *cobra.Command, args []string) error {
+	checks := dc.runChecks(cmd)
+
+	out := cmd.OutOrStdout()
+	if dc.json {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		err := encoder.Encode(struct {
+			Checks []doctorCheck `json:"checks"`
+		}{checks})
+		if err != nil {
+			return err
+		}
+	} else {
+		printDoctorChecks(out, checks)
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if check.Status == doctorFail {
+			failed++
+		}
+	}
+
+	if failed > 0 && dc.json {
+		return ErrReported
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+
+	return nil
+}
+
+func (dc *DoctorCmd) runChecks(cmd *cobra.Command) []doctorCheck {
+	profile := dc.cfg.GetProfile()
+
+	// Nothing else can be checked without a profiles file
+	profilesFile, exists, err := dc.cfg.ResolveProfilesFile()
+	if err != nil {
+		return []doctorCheck{{"profiles file", doctorFail, err.Error()}}
+	}
+
+	if !exists {
+		return []doctorCheck{{"profiles file", doctorFail, fmt.Sprintf("%s does not exist, run `stripe login` to create it", profilesFile)}}
+	}
+
+	checks := []doctorCheck{checkPermissions(profilesFile)}
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+
+	err = v.ReadInConfig()
+	if err != nil {
+		return append(checks, doctorCheck{"profiles file", doctorFail, fmt.Sprintf("%s can't be parsed: %s", profilesFile, err)})
+	}
+
+	checks = append(checks, doctorCheck{"profiles file", doctorPass, fmt.Sprintf("%s is valid", profilesFile)})
+
+	if !v.IsSet(profile.ProfileName) {
+		return append(checks, doctorCheck{"active profile", doctorFail, fmt.Sprintf("the %s profile does not exist, run `stripe login` to create it", profile.ProfileName)})
+	}
+
+	checks = append(checks, doctorCheck{"active profile", doctorPass, fmt.Sprintf("the %s profile exists", profile.ProfileName)})
+
+	apiKey, err := profile.GetStoredAPIKey(false)
+	if err == nil {
+		err = validators.APIKey(apiKey)
+	}
+
+	if err != nil {
+		checks = append(checks, doctorCheck{"test mode API key", doctorFail, err.Error()})
+	} else {
+		checks = append(checks, doctorCheck{"test mode API key", doctorPass, config.RedactAPIKey(apiKey)})
+		checks = append(checks, checkKeyExpiry(profile))
+	}
+
+	checks = append(checks, checkAPIKeySource(profile))
+
+	checks = append(checks, checkKeyring())
+
+	if dc.verify && apiKey != "" {
+		checks = append(checks, dc.checkAPIReachable(cmd, profile, apiKey))
+	}
+
+	return checks
+}
+
+// checkPermissions checks that the profiles file, which holds API keys, can
+// only be read by its owner
+func checkPermissions(profilesFile string) doctorCheck {
+	if !config.FilePermissionsSupported {
+		return doctorCheck{"profiles file permissions", doctorPass, "not checked on Windows"}
+	}
+
+	perm, err := config.CheckFilePermissions(profilesFile)
+	if err != nil {
+		return doctorCheck{"profiles file permissions", doctorFail, err.Error()}
+	}
+
+	return doctorCheck{"profiles file permissions", doctorPass, fmt.Sprintf("%04o", perm)}
+}
+
+func checkKeyExpiry(profile *config.Profile) doctorCheck {
+	expiresAt, err := profile.GetExpiresAt(false)
+	if err == validators.ErrAPIKeyNotConfigured {
+		return doctorCheck{"test mode API key expiry", doctorWarn, "no expiry date recorded"}
+	} else if err != nil {
+		return doctorCheck{"test mode API key expiry", doctorWarn, err.Error()}
+	}
+
+	warnDays, err := profile.GetKeyExpiryWarnDays()
+	if err != nil {
+		return doctorCheck{"test mode API key expiry", doctorWarn, err.Error()}
+	}
+
+	date := expiresAt.Format(config.DateStringFormat)
+
+	status, _ := config.ExpiryStatus(expiresAt, time.Now(), time.Duration(warnDays)*24*time.Hour)
+
+	switch status {
+	case config.ExpiryExpired:
+		return doctorCheck{"test mode API key expiry", doctorFail, fmt.Sprintf("expired on %s, run `stripe login` to get a new key", date)}
+	case config.ExpiryExpiringSoon:
+		return doctorCheck{"test mode API key expiry", doctorWarn, fmt.Sprintf("expires on %s", date)}
+	default:
+		return doctorCheck{"test mode API key expiry", doctorPass, fmt.Sprintf("expires on %s", date)}
+	}
+}
+
+// checkAPIKeySource reports where commands get the test mode API key from,
+// as STRIPE_API_KEY and --api-key take precedence over the stored key
+func checkAPIKeySource(profile *config.Profile) doctorCheck {
+	key, source, err := profile.GetAPIKeyWithSource(false)
+	if err != nil {
+		return doctorCheck{"API key source", doctorWarn, err.Error()}
+	}
+
+	var origin string
+
+	switch source.Source {
+	case config.SourceEnv:
+		origin = "STRIPE_API_KEY"
+	case config.SourceFlag:
+		origin = "the --api-key flag"
+	default:
+		return doctorCheck{"API key source", doctorPass, fmt.Sprintf("the %s profile", profile.ProfileName)}
+	}
+
+	if source.ShadowsConfig {
+		return doctorCheck{"API key source", doctorWarn, fmt.Sprintf("%s (%s) overrides the key stored in the %s profile", origin, config.RedactAPIKey(key), profile.ProfileName)}
+	}
+
+	return doctorCheck{"API key source", doctorPass, fmt.Sprintf("%s (%s)", origin, config.RedactAPIKey(key))}
+}
+
+// checkKeyring checks that live mode keys can be stored. Live mode keys are
+// optional, so an unavailable keyring is only a warning.
+func checkKeyring() doctorCheck {
+	if config.KeyRing == nil {
+		return doctorCheck{"keyring", doctorWarn, "no keyring backend is available, live mode keys can't be stored"}
+	}
+
+	_, err := config.KeyRing.Keys()
+	if err != nil {
+		return doctorCheck{"keyring", doctorWarn, fmt.Sprintf("the keyring can't be read: %s", err)}
+	}
+
+	return doctorCheck{"keyring", doctorPass, "available"}
+}
+
+func (dc *DoctorCmd) checkAPIReachable(cmd *cobra.Command, profile *config.Profile, apiKey string) doctorCheck {
+	fetcher := dc.fetcher
+	if fetcher == nil {
+		fetcher = login.NewHTTPAccountFetcher(profile)
+	}
+
+	account, err := fetcher.GetAccount(cmd.Context(), apiKey)
+	if err != nil {
+		return doctorCheck{"API", doctorFail, err.Error()}
+	}
+
+	stripeAccount := profile.GetStripeAccount()
+
+	connectedFetcher, ok := fetcher.(login.ConnectedAccountFetcher)
+	if !ok || stripeAccount == "" {
+		return doctorCheck{"API", doctorPass, fmt.Sprintf("the test mode API key belongs to %s", account.ID)}
+	}
+
+	_, err = connectedFetcher.GetConnectedAccount(cmd.Context(), apiKey, stripeAccount)
+	if err != nil {
+		return doctorCheck{"API", doctorFail, fmt.Sprintf("the test mode API key belongs to %s but can't access connected account %s: %s", account.ID, stripeAccount, err)}
+	}
+
+	return doctorCheck{"API", doctorPass, fmt.Sprintf("the test mode API key belongs to %s and can access connected account %s", account.ID, stripeAccount)}
+}
+
+func printDoctorChecks(out io.Writer, checks []doctorCheck) {
+	color := ansi.Color(out)
+
+	for _, check := range checks {
+		var status string
+
+		switch check.Status {
+		case doctorPass:
+			status = color.Green("PASS").String()
+		case doctorWarn:
+			status = color.Yellow("WARN").String()
+		default:
+			status = color.Red("FAIL").String()
+		}
+
+		fmt.Fprintf(out, "%s %s: %s\n", status, check.Name, check.Message)
+	}
+}
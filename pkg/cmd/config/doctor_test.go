@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/login/acct"
+)
+
+func setupDoctorConfig(t *testing.T, perm os.FileMode) *config.Config {
+	t.Helper()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	expiresAt := time.Now().AddDate(0, 0, 60).Format(config.DateStringFormat)
+	content := "[default]\ndevice_name = 'st-testing'\ntest_mode_api_key = 'sk_test_1234567890abcd'\ntest_mode_key_expires_at = '" + expiresAt + "'\n"
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+	require.NoError(t, os.Chmod(profilesFile, perm))
+
+	c := &config.Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      config.Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	config.KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	t.Cleanup(viper.Reset)
+
+	return c
+}
+
+func TestDoctorHealthyConfig(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "")
+	c := setupDoctorConfig(t, 0600)
+
+	out, err := executeCmd(t, NewDoctorCmd(c).Cmd, "--json")
+	require.NoError(t, err)
+
+	var result struct {
+		Checks []doctorCheck `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+
+	names := make([]string, 0, len(result.Checks))
+	for _, check := range result.Checks {
+		require.Equal(t, doctorPass, check.Status, check.Name)
+		names = append(names, check.Name)
+	}
+
+	require.Equal(t, []string{
+		"profiles file permissions",
+		"profiles file",
+		"active profile",
+		"test mode API key",
+		"test mode API key expiry",
+		"API key source",
+		"keyring",
+	}, names)
+}
+
+func TestDoctorInsecurePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions are not supported on Windows")
+	}
+
+	c := setupDoctorConfig(t, 0644)
+
+	out, err := executeCmd(t, NewDoctorCmd(c).Cmd)
+	require.EqualError(t, err, "1 of 7 checks failed")
+	require.Contains(t, out, "FAIL profiles file permissions: "+c.ProfilesFile+" has insecure permissions 0644")
+	require.Contains(t, out, "PASS active profile: the default profile exists")
+}
+
+func TestDoctorMissingProfilesFile(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	c := &config.Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      config.Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+
+	out, err := executeCmd(t, NewDoctorCmd(c).Cmd)
+	require.EqualError(t, err, "1 of 1 checks failed")
+	require.Contains(t, out, "FAIL profiles file: "+profilesFile+" does not exist, run `stripe login` to create it")
+}
+
+func TestDoctorAPIKeySource(t *testing.T) {
+	c := setupDoctorConfig(t, 0600)
+
+	t.Setenv("STRIPE_API_KEY", "sk_test_1234567890abcd")
+	out, err := executeCmd(t, NewDoctorCmd(c).Cmd)
+	require.NoError(t, err)
+	require.Contains(t, out, "PASS API key source: STRIPE_API_KEY (sk_test_**********abcd)\n")
+
+	t.Setenv("STRIPE_API_KEY", "sk_test_0000000000wxyz")
+	out, err = executeCmd(t, NewDoctorCmd(c).Cmd)
+	require.NoError(t, err)
+	require.Contains(t, out, "WARN API key source: STRIPE_API_KEY (sk_test_**********wxyz) overrides the key stored in the default profile\n")
+
+	t.Setenv("STRIPE_API_KEY", "")
+	c.Profile.APIKey = "sk_test_0000000000wxyz"
+	out, err = executeCmd(t, NewDoctorCmd(c).Cmd)
+	require.NoError(t, err)
+	require.Contains(t, out, "WARN API key source: the --api-key flag (sk_test_**********wxyz) overrides the key stored in the default profile\n")
+}
+
+func TestDoctorJSONFailureOnlyPrintsJSON(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions are not supported on Windows")
+	}
+
+	c := setupDoctorConfig(t, 0644)
+
+	out, err := executeCmd(t, NewDoctorCmd(c).Cmd, "--json")
+	require.ErrorIs(t, err, ErrReported)
+
+	var result struct {
+		Checks []doctorCheck `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Equal(t, doctorFail, result.Checks[0].Status)
+}
+
+type fakeDoctorFetcher struct {
+	stripeAccounts []string
+	connectedErr   error
+}
+
+func (f *fakeDoctorFetcher) GetAccount(ctx context.Context, apiKey string) (*acct.Account, error) {
+	return f.GetConnectedAccount(ctx, apiKey, "")
+}
+
+func (f *fakeDoctorFetcher) GetConnectedAccount(ctx context.Context, apiKey string, stripeAccount string) (*acct.Account, error) {
+	f.stripeAccounts = append(f.stripeAccounts, stripeAccount)
+
+	if stripeAccount != "" {
+		return &acct.Account{ID: stripeAccount}, f.connectedErr
+	}
+
+	return &acct.Account{ID: "acct_platform"}, nil
+}
+
+func TestDoctorVerifyConnectedAccount(t *testing.T) {
+	c := setupDoctorConfig(t, 0600)
+	require.NoError(t, c.Profile.SetStripeAccount("acct_connected"))
+
+	fetcher := &fakeDoctorFetcher{}
+	dc := NewDoctorCmd(c)
+	dc.fetcher = fetcher
+
+	out, err := executeCmd(t, dc.Cmd, "--verify")
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "acct_connected"}, fetcher.stripeAccounts)
+	require.Contains(t, out, "PASS API: the test mode API key belongs to acct_platform and can access connected account acct_connected\n")
+
+	fetcher.connectedErr = errors.New("failed to retrieve the account: 403 Forbidden")
+
+	out, err = executeCmd(t, dc.Cmd, "--verify")
+	require.EqualError(t, err, "1 of 8 checks failed")
+	require.Contains(t, out, "FAIL API: the test mode API key belongs to acct_platform but can't access connected account acct_connected: failed to retrieve the account: 403 Forbidden\n")
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// hiddenKey replaces API keys in the dump unless --show-keys is used
+const hiddenKey = "[hidden]"
+
+// DumpCmd is the struct used for configuring the dump command
+type DumpCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	json     bool
+	showKeys bool
+}
+
+// NewDumpCmd creates a new command for printing every profile of the config
+// file with API keys hidden
+func NewDumpCmd(cfg *config.Config) *DumpCmd {
+	dc := &DumpCmd{
+		cfg: cfg,
+	}
+
+	dc.Cmd = &cobra.Command{
+		Use:   "dump",
+		Args:  validators.NoArgs,
+		Short: "Print every profile of your config file with API keys hidden",
+		Long: `Print every profile and field of your config file, for example to share it when
+debugging. API keys are hidden, or redacted with --show-keys.`,
+		Example: `stripe config dump --json`,
+		RunE:    dc.runDumpCmd,
+	}
+
+	dc.Cmd.Flags().BoolVar(&dc.json, "json", false, "Output the config as JSON")
+	dc.Cmd.Flags().BoolVar(&dc.showKeys, "show-keys", false, "Show the redacted form of API keys instead of hiding them")
+
+	return dc
+}
+
+func (dc *DumpCmd) runDumpCmd(cmd *cobra.Command, args []string) error {
+	v := viper.New()
+	v.SetConfigFile(dc.cfg.ProfilesFile)
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	settings := redactSettings(v.AllSettings(), dc.showKeys)
+
+	out := cmd.OutOrStdout()
+	if dc.json {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(settings)
+	}
+
+	return toml.NewEncoder(out).Encode(settings)
+}
+
+// redactSettings hides the API keys found in settings, or redacts them with
+// config.RedactAPIKey when showKeys is set
+func redactSettings(settings map[string]interface{}, showKeys bool) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(settings))
+
+	for key, value := range settings {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			redacted[key] = redactSettings(value, showKeys)
+		case string:
+			redacted[key] = redactValue(value, showKeys)
+		default:
+			redacted[key] = value
+		}
+	}
+
+	return redacted
+}
+
+func redactValue(value string, showKeys bool) string {
+	if _, _, err := validators.ParseAPIKey(value); err != nil {
+		return value
+	}
+
+	if showKeys {
+		return config.RedactAPIKey(value)
+	}
+
+	return hiddenKey
+}
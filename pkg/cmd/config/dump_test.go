@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func executeDump(t *testing.T, args ...string) map[string]interface{} {
+	t.Helper()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := `color = 'on'
+
+[default]
+device_name = 'st-testing'
+test_mode_api_key = 'sk_test_1234567890abcd'
+
+[staging]
+account_id = 'acct_123'
+test_mode_api_key = 'rk_test_0987654321wxyz'
+test_mode_pub_key = 'pk_test_5555555555pppp'
+`
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	out, err := executeCmd(t, NewDumpCmd(&config.Config{ProfilesFile: profilesFile}).Cmd, append([]string{"--json"}, args...)...)
+	require.NoError(t, err)
+
+	require.NotContains(t, out, "1234567890")
+	require.NotContains(t, out, "0987654321")
+
+	var dump map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &dump))
+
+	return dump
+}
+
+func TestDumpHidesKeys(t *testing.T) {
+	dump := executeDump(t)
+
+	require.Equal(t, map[string]interface{}{
+		"color": "on",
+		"default": map[string]interface{}{
+			"device_name":       "st-testing",
+			"test_mode_api_key": "[hidden]",
+		},
+		"staging": map[string]interface{}{
+			"account_id":        "acct_123",
+			"test_mode_api_key": "[hidden]",
+			"test_mode_pub_key": "[hidden]",
+		},
+	}, dump)
+}
+
+func TestDumpShowKeys(t *testing.T) {
+	dump := executeDump(t, "--show-keys")
+
+	require.Equal(t, "sk_test_**********abcd", dump["default"].(map[string]interface{})["test_mode_api_key"])
+	require.Equal(t, "rk_test_**********wxyz", dump["staging"].(map[string]interface{})["test_mode_api_key"])
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// executeCmd runs cmd with args and returns what it printed. Usage and errors
+// are silenced, as the root command does.
+func executeCmd(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	// cobra falls back to os.Args when args are nil
+	cmd.SetArgs(append([]string{}, args...))
+
+	err := cmd.Execute()
+
+	return out.String(), err
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// securePermissions are the permissions of files holding API keys
+const securePermissions = os.FileMode(0600)
+
+// PermissionsFixCmd is the struct used for configuring the permissions-fix
+// command
+type PermissionsFixCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	dryRun bool
+}
+
+// NewPermissionsFixCmd creates a new command for making the config file only
+// readable by its owner
+func NewPermissionsFixCmd(cfg *config.Config) *PermissionsFixCmd {
+	pc := &PermissionsFixCmd{
+		cfg: cfg,
+	}
+
+	pc.Cmd = &cobra.Command{
+		Use:   "permissions-fix",
+		Args:  validators.NoArgs,
+		Short: "Make your config file only readable by you",
+		Long: `Make your config file, which holds API keys, only readable and writable by you
+(0600). Permissions are not changed on Windows.
+
+The default config file is already made private every time the CLI runs, so
+this is only needed for a config file given with --config or
+STRIPE_CONFIG_FILE.`,
+		Example: `stripe config permissions-fix --dry-run`,
+		RunE:    pc.runPermissionsFixCmd,
+	}
+
+	pc.Cmd.Flags().BoolVar(&pc.dryRun, "dry-run", false, "Report the permissions that would be changed without changing them")
+
+	return pc
+}
+
+func (pc *PermissionsFixCmd) runPermissionsFixCmd(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	path := pc.cfg.ProfilesFile
+
+	if !config.FilePermissionsSupported {
+		fmt.Fprintf(out, "Permissions are not supported on Windows, %s was left unchanged\n", path)
+		return nil
+	}
+
+	perm, err := config.CheckFilePermissions(path)
+	if err == nil {
+		fmt.Fprintf(out, "%s already has secure permissions %04o\n", path, perm)
+		return nil
+	} else if !errors.Is(err, config.ErrInsecurePermissions) {
+		return err
+	}
+
+	if pc.dryRun {
+		fmt.Fprintf(out, "Would change the permissions of %s from %04o to %04o\n", path, perm, securePermissions)
+		return nil
+	}
+
+	err = os.Chmod(path, securePermissions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Changed the permissions of %s from %04o to %04o\n", path, perm, securePermissions)
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func executePermissionsFix(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions are not supported on Windows")
+	}
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[default]\n"), 0600))
+	require.NoError(t, os.Chmod(profilesFile, 0644))
+
+	out, err := executeCmd(t, NewPermissionsFixCmd(&config.Config{ProfilesFile: profilesFile}).Cmd, args...)
+	require.NoError(t, err)
+
+	return profilesFile, out
+}
+
+func TestPermissionsFix(t *testing.T) {
+	profilesFile, out := executePermissionsFix(t)
+	require.Equal(t, "Changed the permissions of "+profilesFile+" from 0644 to 0600\n", out)
+
+	info, err := os.Stat(profilesFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestPermissionsFixDryRun(t *testing.T) {
+	profilesFile, out := executePermissionsFix(t, "--dry-run")
+	require.Equal(t, "Would change the permissions of "+profilesFile+" from 0644 to 0600\n", out)
+
+	info, err := os.Stat(profilesFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
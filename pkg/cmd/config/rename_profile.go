@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// RenameProfileCmd is the struct used for configuring the rename-profile command
+type RenameProfileCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	force bool
+}
+
+// NewRenameProfileCmd creates a new command for renaming a profile
+func NewRenameProfileCmd(cfg *config.Config) *RenameProfileCmd {
+	rc := &RenameProfileCmd{
+		cfg: cfg,
+	}
+
+	rc.Cmd = &cobra.Command{
+		Use:     "rename-profile <old> <new>",
+		Args:    validators.ExactArgs(2),
+		Short:   "Rename a profile",
+		Long:    `Rename a profile, carrying over all of its fields and the keys stored in the keyring.`,
+		Example: `stripe config rename-profile staging sandbox`,
+		RunE:    rc.runRenameProfileCmd,
+	}
+
+	rc.Cmd.Flags().BoolVar(&rc.force, "force", false, "Replace the new profile if it already exists")
+
+	return rc
+}
+
+func (rc *RenameProfileCmd) runRenameProfileCmd(cmd *cobra.Command, args []string) error {
+	err := rc.cfg.RenameProfile(args[0], args[1], rc.force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Renamed profile %s to %s\n", args[0], args[1])
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func setupRenameProfile(t *testing.T) *config.Config {
+	t.Helper()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := "[staging]\ndevice_name = 'staging-device'\n\n[sandbox]\ndevice_name = 'sandbox-device'\n"
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	c := &config.Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      config.Profile{ProfileName: "staging"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	config.KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+	t.Cleanup(viper.Reset)
+
+	return c
+}
+
+func readProfilesFile(t *testing.T, c *config.Config) *viper.Viper {
+	t.Helper()
+
+	v := viper.New()
+	v.SetConfigFile(c.ProfilesFile)
+	require.NoError(t, v.ReadInConfig())
+
+	return v
+}
+
+func TestRenameProfileCmd(t *testing.T) {
+	c := setupRenameProfile(t)
+
+	out, err := executeCmd(t, NewRenameProfileCmd(c).Cmd, "staging", "preprod")
+	require.NoError(t, err)
+	require.Equal(t, "Renamed profile staging to preprod\n", out)
+
+	v := readProfilesFile(t, c)
+	require.False(t, v.IsSet("staging"))
+	require.Equal(t, "staging-device", v.GetString("preprod.device_name"))
+}
+
+func TestRenameProfileCmdExisting(t *testing.T) {
+	c := setupRenameProfile(t)
+
+	out, err := executeCmd(t, NewRenameProfileCmd(c).Cmd, "staging", "sandbox")
+	require.EqualError(t, err, "profile sandbox already exists, use --force to replace it")
+	require.Empty(t, out)
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "staging-device", v.GetString("staging.device_name"))
+	require.Equal(t, "sandbox-device", v.GetString("sandbox.device_name"))
+}
+
+func TestRenameProfileCmdForce(t *testing.T) {
+	c := setupRenameProfile(t)
+
+	out, err := executeCmd(t, NewRenameProfileCmd(c).Cmd, "staging", "sandbox", "--force")
+	require.NoError(t, err)
+	require.Equal(t, "Renamed profile staging to sandbox\n", out)
+
+	v := readProfilesFile(t, c)
+	require.False(t, v.IsSet("staging"))
+	require.Equal(t, "staging-device", v.GetString("sandbox.device_name"))
+}
+
+func TestRenameProfileCmdMissing(t *testing.T) {
+	c := setupRenameProfile(t)
+
+	_, err := executeCmd(t, NewRenameProfileCmd(c).Cmd, "prod", "live")
+	require.EqualError(t, err, "profile 'prod' not found")
+}
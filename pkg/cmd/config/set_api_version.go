@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// SetAPIVersionCmd is the struct used for configuring the set-api-version command
+type SetAPIVersionCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+}
+
+// NewSetAPIVersionCmd creates a new command for pinning the profile to a
+// Stripe API version
+func NewSetAPIVersionCmd(cfg *config.Config) *SetAPIVersionCmd {
+	sc := &SetAPIVersionCmd{
+		cfg: cfg,
+	}
+
+	sc.Cmd = &cobra.Command{
+		Use:   "set-api-version <version>",
+		Args:  validators.ExactArgs(1),
+		Short: "Pin the profile to a Stripe API version",
+		Long: `Pin the profile to a Stripe API version, formatted as YYYY-MM-DD. The version is
+sent as the Stripe-Version header when verifying API keys.`,
+		Example: `stripe config set-api-version 2024-06-20`,
+		RunE:    sc.runSetAPIVersionCmd,
+	}
+
+	return sc
+}
+
+func (sc *SetAPIVersionCmd) runSetAPIVersionCmd(cmd *cobra.Command, args []string) error {
+	err := sc.cfg.Profile.SetAPIVersion(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Profile %s now uses API version %s\n", sc.cfg.Profile.ProfileName, args[0])
+
+	return nil
+}
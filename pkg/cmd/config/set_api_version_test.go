@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func executeSetAPIVersion(t *testing.T, version string) (*config.Config, string, error) {
+	t.Helper()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[staging]\ndevice_name = 'st-testing'\n"), 0600))
+
+	c := &config.Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      config.Profile{ProfileName: "staging"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	t.Cleanup(viper.Reset)
+
+	out, err := executeCmd(t, NewSetAPIVersionCmd(c).Cmd, version)
+
+	return c, out, err
+}
+
+func TestSetAPIVersion(t *testing.T) {
+	c, out, err := executeSetAPIVersion(t, "2024-06-20")
+	require.NoError(t, err)
+	require.Equal(t, "Profile staging now uses API version 2024-06-20\n", out)
+
+	v := viper.New()
+	v.SetConfigFile(c.ProfilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "2024-06-20", v.GetString("staging.api_version"))
+	require.Equal(t, "st-testing", v.GetString("staging.device_name"))
+}
+
+func TestSetAPIVersionInvalid(t *testing.T) {
+	c, _, err := executeSetAPIVersion(t, "latest")
+	require.EqualError(t, err, "api_version must be formatted as YYYY-MM-DD: latest")
+	require.Empty(t, c.Profile.GetAPIVersion())
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// keyringItem describes an item the CLI stored in the keyring, never its value
+type keyringItem struct {
+	Name     string `json:"name"`
+	Profile  string `json:"profile"`
+	Field    string `json:"field"`
+	Orphaned bool   `json:"orphaned"`
+}
+
+// ShowKeyringItemsCmd is the struct used for configuring the
+// show-keyring-items command
+type ShowKeyringItemsCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	json bool
+}
+
+// NewShowKeyringItemsCmd creates a new command for listing the names of the
+// items stored in the keyring
+func NewShowKeyringItemsCmd(cfg *config.Config) *ShowKeyringItemsCmd {
+	sc := &ShowKeyringItemsCmd{
+		cfg: cfg,
+	}
+
+	sc.Cmd = &cobra.Command{
+		Use:   "show-keyring-items",
+		Args:  validators.NoArgs,
+		Short: "List the items stored in the keyring",
+		Long: `List the names of the items the CLI stored in the keyring, never their values.
+Items of profiles that no longer exist in your config file are marked as
+orphaned.`,
+		Example: `stripe config show-keyring-items --json`,
+		RunE:    sc.runShowKeyringItemsCmd,
+	}
+
+	sc.Cmd.Flags().BoolVar(&sc.json, "json", false, "Output the items as JSON")
+
+	return sc
+}
+
+func (sc *ShowKeyringItemsCmd) runShowKeyringItemsCmd(cmd *cobra.Command, args []string) error {
+	if config.KeyRing == nil {
+		return config.ErrKeyringUnavailable
+	}
+
+	keys, err := config.KeyRing.Keys()
+	if err != nil {
+		return fmt.Errorf("%w: %s", config.ErrKeyringUnavailable, err)
+	}
+
+	// A missing config file means every item is orphaned
+	v := viper.New()
+	v.SetConfigFile(sc.cfg.ProfilesFile)
+	v.ReadInConfig()
+
+	items := listKeyringItems(keys, v)
+
+	out := cmd.OutOrStdout()
+	if sc.json {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(struct {
+			Items []keyringItem `json:"items"`
+		}{items})
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintln(out, "No items are stored in the keyring")
+		return nil
+	}
+
+	for _, item := range items {
+		if item.Orphaned {
+			fmt.Fprintf(out, "%s (orphaned, the %s profile does not exist)\n", item.Name, item.Profile)
+		} else {
+			fmt.Fprintln(out, item.Name)
+		}
+	}
+
+	return nil
+}
+
+// listKeyringItems returns the items named <profile>.<field>, the names the
+// CLI stores items under, sorted by name
+func listKeyringItems(keys []string, v *viper.Viper) []keyringItem {
+	items := []keyringItem{}
+
+	for _, key := range keys {
+		profile, field, found := strings.Cut(key, ".")
+		if !found || profile == "" || field == "" {
+			continue
+		}
+
+		_, isProfile := v.Get(profile).(map[string]interface{})
+
+		items = append(items, keyringItem{
+			Name:     key,
+			Profile:  profile,
+			Field:    field,
+			Orphaned: !isProfile,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
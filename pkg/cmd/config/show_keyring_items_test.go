@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func TestShowKeyringItems(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[default]\ntest_mode_api_key = 'sk_test_**********abcd'\n"), 0600))
+
+	config.KeyRing = keyring.NewArrayKeyring([]keyring.Item{
+		{Key: "default.test_mode_api_key", Data: []byte("sk_test_1234567890abcd")},
+		{Key: "removed.live_mode_api_key", Data: []byte("rk_live_1234567890abcd")},
+		{Key: "unrelated", Data: []byte("secret")},
+	})
+	t.Cleanup(func() { config.KeyRing = nil })
+
+	sc := NewShowKeyringItemsCmd(&config.Config{ProfilesFile: profilesFile})
+
+	out, err := executeCmd(t, sc.Cmd)
+	require.NoError(t, err)
+	require.Equal(t, "default.test_mode_api_key\nremoved.live_mode_api_key (orphaned, the removed profile does not exist)\n", out)
+	require.NotContains(t, out, "1234567890")
+
+	out, err = executeCmd(t, sc.Cmd, "--json")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"items": [
+		{"name": "default.test_mode_api_key", "profile": "default", "field": "test_mode_api_key", "orphaned": false},
+		{"name": "removed.live_mode_api_key", "profile": "removed", "field": "live_mode_api_key", "orphaned": true}
+	]}`, out)
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/keyring"
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// keyringTestItem is the key of the dummy item written to the keyring
+const keyringTestItem = "stripe-cli-keyring-test"
+
+// keyringTestResult is the outcome of checking the keyring
+type keyringTestResult struct {
+	Success    bool   `json:"success"`
+	FailedStep string `json:"failed_step,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TestKeyringCmd is the struct used for configuring the test-keyring command
+type TestKeyringCmd struct {
+	Cmd *cobra.Command
+
+	json bool
+}
+
+// NewTestKeyringCmd creates a new command for checking that the keyring can
+// store secrets
+func NewTestKeyringCmd() *TestKeyringCmd {
+	tc := &TestKeyringCmd{}
+
+	tc.Cmd = &cobra.Command{
+		Use:   "test-keyring",
+		Args:  validators.NoArgs,
+		Short: "Check that the keyring can store secrets",
+		Long: `Check that the keyring can store secrets by writing a dummy item to it,
+reading it back and deleting it.`,
+		Example: `stripe config test-keyring`,
+		RunE:    tc.runTestKeyringCmd,
+	}
+
+	tc.Cmd.Flags().BoolVar(&tc.json, "json", false, "Output the result as JSON")
+
+	return tc
+}
+
+func (tc *TestKeyringCmd) runTestKeyringCmd(cmd *cobra.Command, args []string) error {
+	result := testKeyring(config.KeyRing)
+
+	out := cmd.OutOrStdout()
+	if tc.json {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		err := encoder.Encode(result)
+		if err != nil {
+			return err
+		}
+	} else if result.Success {
+		fmt.Fprintln(out, "The keyring works: a test item was stored, retrieved and deleted")
+	}
+
+	if !result.Success && tc.json {
+		return ErrReported
+	}
+
+	if !result.Success {
+		return fmt.Errorf("the keyring check failed to %s the test item: %s", result.FailedStep, result.Error)
+	}
+
+	return nil
+}
+
+// testKeyring writes a dummy item to kr, reads it back and deletes it
+func testKeyring(kr keyring.Keyring) keyringTestResult {
+	if kr == nil {
+		return keyringTestResult{FailedStep: "open", Error: config.ErrKeyringUnavailable.Error()}
+	}
+
+	value := []byte("stripe-cli")
+
+	err := kr.Set(keyring.Item{
+		Key:         keyringTestItem,
+		Data:        value,
+		Label:       keyringTestItem,
+		Description: "Stripe CLI keyring test",
+	})
+	if err != nil {
+		return keyringTestResult{FailedStep: "store", Error: err.Error()}
+	}
+
+	item, err := kr.Get(keyringTestItem)
+	if err == nil && string(item.Data) != string(value) {
+		err = errors.New("the retrieved value differs from the stored one")
+	}
+
+	if err != nil {
+		// Don't leave the test item behind
+		kr.Remove(keyringTestItem)
+		return keyringTestResult{FailedStep: "retrieve", Error: err.Error()}
+	}
+
+	err = kr.Remove(keyringTestItem)
+	if err != nil {
+		return keyringTestResult{FailedStep: "delete", Error: err.Error()}
+	}
+
+	return keyringTestResult{Success: true}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func TestTestKeyringSuccess(t *testing.T) {
+	config.KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+	t.Cleanup(func() { config.KeyRing = nil })
+
+	out, err := executeCmd(t, NewTestKeyringCmd().Cmd, "--json")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"success": true}`, out)
+
+	keys, err := config.KeyRing.Keys()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestTestKeyringUnavailable(t *testing.T) {
+	config.KeyRing = nil
+
+	result := testKeyring(config.KeyRing)
+	require.Equal(t, keyringTestResult{FailedStep: "open", Error: "the keyring is unavailable"}, result)
+}
+
+func TestTestKeyringFailureJSON(t *testing.T) {
+	config.KeyRing = nil
+
+	out, err := executeCmd(t, NewTestKeyringCmd().Cmd, "--json")
+	require.ErrorIs(t, err, ErrReported)
+
+	var result keyringTestResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Equal(t, keyringTestResult{FailedStep: "open", Error: "the keyring is unavailable"}, result)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// UseCmd is the struct used for configuring the use command
+type UseCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+}
+
+// NewUseCmd creates a new command for setting the active profile
+func NewUseCmd(cfg *config.Config) *UseCmd {
+	uc := &UseCmd{
+		cfg: cfg,
+	}
+
+	uc.Cmd = &cobra.Command{
+		Use:   "use <profile>",
+		Args:  validators.ExactArgs(1),
+		Short: "Set the active profile",
+		Long: `Set the profile commands use when no project name is given with --project-name
+or STRIPE_PROJECT_NAME.`,
+		Example: `stripe config use staging`,
+		RunE:    uc.runUseCmd,
+	}
+
+	return uc
+}
+
+func (uc *UseCmd) runUseCmd(cmd *cobra.Command, args []string) error {
+	err := uc.cfg.SetDefaultProfile(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Now using profile %s\n", args[0])
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/stripe/stripe-cli/pkg/ansi"
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// severities of a validation diagnostic
+const (
+	validateError   = "error"
+	validateWarning = "warning"
+)
+
+// knownProfileFields are the fields a profile may hold, including the legacy
+// ones still read for older config files
+var knownProfileFields = map[string]bool{
+	config.AccountIDName:              true,
+	config.DeviceNameName:             true,
+	config.DisplayNameName:            true,
+	config.IsTermsAcceptanceValidName: true,
+	config.TestModeAPIKeyName:         true,
+	config.TestModePubKeyName:         true,
+	config.TestModeKeyExpiresAtName:   true,
+	config.LiveModeAPIKeyName:         true,
+	config.LiveModePubKeyName:         true,
+	config.LiveModeKeyExpiresAtName:   true,
+	config.KeyExpiryWarnDaysName:      true,
+	config.APIVersionName:             true,
+	config.StripeAccountName:          true,
+	"color":                           true,
+	"experimental":                    true,
+	"terminal_pos_device_id":          true,
+	"api_key":                         true,
+	"secret_key":                      true,
+	"publishable_key":                 true,
+	"test_mode_publishable_key":       true,
+}
+
+// profileDiagnostic is a problem found in a profile of the config file
+type profileDiagnostic struct {
+	Profile  string `json:"profile"`
+	Field    string `json:"field"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// ValidateCmd is the struct used for configuring the validate command
+type ValidateCmd struct {
+	cfg *config.Config
+	Cmd *cobra.Command
+
+	json bool
+}
+
+// NewValidateCmd creates a new command for validating every profile of the
+// config file
+func NewValidateCmd(cfg *config.Config) *ValidateCmd {
+	vc := &ValidateCmd{
+		cfg: cfg,
+	}
+
+	vc.Cmd = &cobra.Command{
+		Use:   "validate",
+		Args:  validators.NoArgs,
+		Short: "Check every profile of your config file for mistakes",
+		Long: `Check every profile of your config file for malformed API keys, expiry dates
+not in the YYYY-MM-DD format and unknown fields. Exits with a non-zero status if
+any error is found. Unknown fields and expiry dates in other formats the CLI can
+still read are only reported as warnings.`,
+		Example: `stripe config validate
+  stripe config validate --json`,
+		RunE: vc.runValidateCmd,
+	}
+
+	vc.Cmd.Flags().BoolVar(&vc.json, "json", false, "Output the diagnostics as JSON")
+
+	return vc
+}
+
+func (vc *ValidateCmd) runValidateCmd(cmd *cobra.Command, args []string) error {
+	profilesFile, exists, err := vc.cfg.ResolveProfilesFile()
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%s does not exist, run `stripe login` to create it", profilesFile)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+
+	err = v.ReadInConfig()
+	if err != nil {
+		return fmt.Errorf("%s can't be parsed: %w", profilesFile, err)
+	}
+
+	profiles, diagnostics := validateProfiles(v.AllSettings())
+
+	out := cmd.OutOrStdout()
+	if vc.json {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(struct {
+			Profiles    []string            `json:"profiles"`
+			Diagnostics []profileDiagnostic `json:"diagnostics"`
+		}{profiles, diagnostics})
+		if err != nil {
+			return err
+		}
+	} else {
+		printProfileDiagnostics(out, profiles, diagnostics)
+	}
+
+	errors := 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == validateError {
+			errors++
+		}
+	}
+
+	if errors > 0 && vc.json {
+		return ErrReported
+	}
+
+	if errors > 0 {
+		return fmt.Errorf("%d errors found in %s", errors, profilesFile)
+	}
+
+	return nil
+}
+
+// validateProfiles returns the sorted names of the profiles in settings and
+// the problems found in them
+func validateProfiles(settings map[string]interface{}) ([]string, []profileDiagnostic) {
+	profiles := []string{}
+	diagnostics := []profileDiagnostic{}
+
+	for name, value := range settings {
+		if _, ok := value.(map[string]interface{}); ok {
+			profiles = append(profiles, name)
+		}
+	}
+
+	sort.Strings(profiles)
+
+	for _, name := range profiles {
+		fields := settings[name].(map[string]interface{})
+
+		keys := make([]string, 0, len(fields))
+		for field := range fields {
+			keys = append(keys, field)
+		}
+
+		sort.Strings(keys)
+
+		for _, field := range keys {
+			diagnostic := validateProfileField(field, fields[field])
+			if diagnostic != nil {
+				diagnostic.Profile = name
+				diagnostic.Field = field
+				diagnostics = append(diagnostics, *diagnostic)
+			}
+		}
+	}
+
+	return profiles, diagnostics
+}
+
+func validateProfileField(field string, value interface{}) *profileDiagnostic {
+	if !knownProfileFields[field] {
+		return &profileDiagnostic{Severity: validateWarning, Message: "unknown field"}
+	}
+
+	switch field {
+	case config.TestModeAPIKeyName, config.LiveModeAPIKeyName:
+		if err := validators.APIKey(fmt.Sprint(value)); err != nil {
+			return &profileDiagnostic{Severity: validateError, Message: err.Error()}
+		}
+	case config.TestModeKeyExpiresAtName, config.LiveModeKeyExpiresAtName:
+		if _, err := time.Parse(config.DateStringFormat, fmt.Sprint(value)); err == nil {
+			return nil
+		}
+
+		// Other date formats are still read, but the CLI always writes YYYY-MM-DD
+		expiresAt, err := config.ParseExpiresAt(field, fmt.Sprint(value))
+		if err != nil {
+			return &profileDiagnostic{Severity: validateError, Message: fmt.Sprintf("%v is not a date in the YYYY-MM-DD format", value)}
+		}
+
+		return &profileDiagnostic{Severity: validateWarning, Message: fmt.Sprintf("%v is not in the YYYY-MM-DD format, use %s", value, expiresAt.Format(config.DateStringFormat))}
+	}
+
+	return nil
+}
+
+func printProfileDiagnostics(out io.Writer, profiles []string, diagnostics []profileDiagnostic) {
+	color := ansi.Color(out)
+
+	for _, profile := range profiles {
+		fmt.Fprintf(out, "[%s]\n", profile)
+
+		found := false
+
+		for _, diagnostic := range diagnostics {
+			if diagnostic.Profile != profile {
+				continue
+			}
+
+			found = true
+
+			severity := color.Yellow("WARN").String()
+			if diagnostic.Severity == validateError {
+				severity = color.Red("ERROR").String()
+			}
+
+			fmt.Fprintf(out, "  %s %s: %s\n", severity, diagnostic.Field, diagnostic.Message)
+		}
+
+		if !found {
+			fmt.Fprintf(out, "  %s\n", color.Green("OK"))
+		}
+	}
+}
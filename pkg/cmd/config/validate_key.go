@@ -0,0 +1,86 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// ValidateKeyCmd is the struct used for configuring the validate-key command
+type ValidateKeyCmd struct {
+	Cmd *cobra.Command
+
+	redact string
+}
+
+// NewValidateKeyCmd creates a new command for checking whether an API key is
+// well-formed
+func NewValidateKeyCmd() *ValidateKeyCmd {
+	vc := &ValidateKeyCmd{}
+
+	vc.Cmd = &cobra.Command{
+		Use:   "validate-key <key>",
+		Args:  validators.ExactArgs(1),
+		Short: "Check that an API key is well-formed",
+		Long: `Check that an API key is well-formed and report its mode and type, without
+saving it to your config. Pass "-" to read the key from stdin.`,
+		Example: `stripe config validate-key sk_test_123...
+  echo "$STRIPE_API_KEY" | stripe config validate-key -`,
+		RunE: vc.runValidateKeyCmd,
+	}
+
+	vc.Cmd.Flags().StringVar(&vc.redact, "redact", "full", "How to redact the echoed key (full, partial, fingerprint)")
+
+	return vc
+}
+
+func (vc *ValidateKeyCmd) runValidateKeyCmd(cmd *cobra.Command, args []string) error {
+	redactor, err := config.GetRedactor(vc.redact)
+	if err != nil {
+		return err
+	}
+
+	key := args[0]
+	if key == "-" {
+		var err error
+
+		key, err = readKey(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+	}
+
+	key = strings.TrimSpace(key)
+
+	err = validators.APIKey(key)
+	if err != nil {
+		return err
+	}
+
+	mode, keyType, err := validators.ParseAPIKey(key)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Key: %s\n", redactor.Redact(key))
+	fmt.Fprintf(out, "Mode: %s\n", mode)
+	fmt.Fprintf(out, "Type: %s\n", keyType)
+
+	return nil
+}
+
+func readKey(input io.Reader) (string, error) {
+	key, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func executeValidateKey(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	vc := NewValidateKeyCmd()
+	vc.Cmd.SetIn(strings.NewReader(stdin))
+
+	return executeCmd(t, vc.Cmd, args...)
+}
+
+func TestValidateKeyTestSecretKey(t *testing.T) {
+	out, err := executeValidateKey(t, "", "sk_test_1234567890abcd")
+	require.NoError(t, err)
+	require.Equal(t, "Key: sk_test_**********abcd\nMode: test\nType: secret\n", out)
+	require.NotContains(t, out, "1234567890")
+}
+
+func TestValidateKeyFingerprint(t *testing.T) {
+	out, err := executeValidateKey(t, "", "--redact", "fingerprint", "sk_test_1234567890abcd")
+	require.NoError(t, err)
+	require.Equal(t, "Key: "+config.APIKeyFingerprint("sk_test_1234567890abcd")+"\nMode: test\nType: secret\n", out)
+}
+
+func TestValidateKeyStdin(t *testing.T) {
+	out, err := executeValidateKey(t, "rk_live_1234567890abcd\n", "-")
+	require.NoError(t, err)
+	require.Equal(t, "Key: rk_live_**********abcd\nMode: live\nType: restricted\n", out)
+}
+
+func TestValidateKeyInvalidKey(t *testing.T) {
+	out, err := executeValidateKey(t, "", "sk_test")
+	require.EqualError(t, err, "the API key provided is too short, it must be at least 12 characters long")
+	require.Empty(t, out)
+}
+
+func TestValidateKeyPublishableKey(t *testing.T) {
+	out, err := executeValidateKey(t, "", "pk_test_1234567890abcd")
+	require.EqualError(t, err, "the CLI only supports using a secret or restricted key")
+	require.Empty(t, out)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+func executeValidate(t *testing.T, content string, args ...string) (string, error) {
+	t.Helper()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	return executeCmd(t, NewValidateCmd(&config.Config{ProfilesFile: profilesFile}).Cmd, args...)
+}
+
+func TestValidateValidConfig(t *testing.T) {
+	out, err := executeValidate(t, "color = 'on'\n\n[default]\ntest_mode_api_key = 'sk_test_1234567890abcd'\ntest_mode_key_expires_at = '2030-01-02'\n")
+	require.NoError(t, err)
+	require.Equal(t, "[default]\n  OK\n", out)
+}
+
+func TestValidateMalformedKey(t *testing.T) {
+	out, err := executeValidate(t, "[default]\ntest_mode_api_key = 'sk_test_1234567890abcd'\n\n[staging]\ntest_mode_api_key = 'pk_test_1234567890abcd'\n")
+	require.ErrorContains(t, err, "1 errors found in")
+	require.Equal(t, "[default]\n  OK\n[staging]\n  ERROR test_mode_api_key: the CLI only supports using a secret or restricted key\n", out)
+}
+
+func TestValidateBadDate(t *testing.T) {
+	out, err := executeValidate(t, "[default]\ntest_mode_api_key = 'sk_test_1234567890abcd'\ntest_mode_key_expires_at = 'next year'\nfoo = 'bar'\n", "--json")
+	require.ErrorIs(t, err, ErrReported)
+
+	var result struct {
+		Profiles    []string            `json:"profiles"`
+		Diagnostics []profileDiagnostic `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Equal(t, []string{"default"}, result.Profiles)
+	require.Equal(t, []profileDiagnostic{
+		{Profile: "default", Field: "foo", Severity: "warning", Message: "unknown field"},
+		{Profile: "default", Field: "test_mode_key_expires_at", Severity: "error", Message: "next year is not a date in the YYYY-MM-DD format"},
+	}, result.Diagnostics)
+}
+
+func TestValidateFallbackDate(t *testing.T) {
+	out, err := executeValidate(t, "[default]\ntest_mode_api_key = 'sk_test_1234567890abcd'\ntest_mode_key_expires_at = '01/02/2030'\nlive_mode_key_expires_at = '2030/01/02'\n")
+	require.NoError(t, err)
+	require.Equal(t, "[default]\n  WARN live_mode_key_expires_at: 2030/01/02 is not in the YYYY-MM-DD format, use 2030-01-02\n  WARN test_mode_key_expires_at: 01/02/2030 is not in the YYYY-MM-DD format, use 2030-01-02\n", out)
+}
+
+func TestValidateErrorsWithoutJSON(t *testing.T) {
+	_, err := executeValidate(t, "[default]\ntest_mode_key_expires_at = 'next year'\n")
+	require.ErrorContains(t, err, "1 errors found in")
+}
+
+func TestValidateSortsProfiles(t *testing.T) {
+	out, err := executeValidate(t, "[staging]\ncolor = 'on'\n[default]\ncolor = 'on'\n[acme]\ncolor = 'on'\n[zeta]\ncolor = 'on'\n")
+	require.NoError(t, err)
+	require.Equal(t, "[acme]\n  OK\n[default]\n  OK\n[staging]\n  OK\n[zeta]\n  OK\n", out)
+}
+
+func TestValidateMissingProfilesFile(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	_, err := executeCmd(t, NewValidateCmd(&config.Config{ProfilesFile: profilesFile}).Cmd)
+	require.EqualError(t, err, profilesFile+" does not exist, run `stripe login` to create it")
+}
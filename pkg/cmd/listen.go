@@ -51,7 +51,6 @@ type listenCmd struct {
 	skipUpdate            bool
 	apiBaseURL            string
 	noWSS                 bool
-	timeout               int64
 	deviceToken           string
 }
 
@@ -101,9 +100,6 @@ Stripe account.`,
 	lc.cmd.Flags().BoolVar(&lc.noWSS, "no-wss", false, "Force unencrypted ws:// protocol instead of wss://")
 	lc.cmd.Flags().MarkHidden("no-wss") // #nosec G104
 
-	lc.cmd.Flags().Int64Var(&lc.timeout, "timeout", 30, "Sets timeout duration")
-	lc.cmd.Flags().MarkHidden("timeout")
-
 	// renamed --load-from-webhooks-api to --use-configured-webhooks,  but want to keep backward compatibility
 	lc.cmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
 		if name == "load-from-webhooks-api" {
@@ -190,7 +186,7 @@ func (lc *listenCmd) runListenCmd(cmd *cobra.Command, args []string) error {
 		SkipVerify:            lc.skipVerify,
 		Log:                   logger,
 		NoWSS:                 lc.noWSS,
-		Timeout:               lc.timeout,
+		Timeout:               forwardTimeoutSeconds(stripe.DefaultTimeout),
 		Events:                lc.events,
 		ThinEvents:            lc.thinEvents,
 		OutCh:                 proxyOutCh,
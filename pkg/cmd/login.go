@@ -1,21 +1,70 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	exec "golang.org/x/sys/execabs"
+	"golang.org/x/term"
 
+	"github.com/stripe/stripe-cli/pkg/clipboard"
+	"github.com/stripe/stripe-cli/pkg/config"
 	"github.com/stripe/stripe-cli/pkg/login"
 	"github.com/stripe/stripe-cli/pkg/stripe"
 	"github.com/stripe/stripe-cli/pkg/validators"
 )
 
+// apiKeyCommandTimeout is how long the --api-key-command command may run
+const apiKeyCommandTimeout = 30 * time.Second
+
 type loginCmd struct {
-	cmd              *cobra.Command
-	interactive      bool
-	dashboardBaseURL string
+	cmd                 *cobra.Command
+	interactive         bool
+	interactiveFallback bool
+	force               bool
+	dryRun              bool
+	outputProfileOnly   bool
+	json                bool
+	keyring             bool
+	verify              bool
+	testMode            bool
+	liveMode            bool
+	fromClipboard       bool
+	apiKeyCommand       string
+	credentialsFile     string
+	stripeAccount       string
+	auditFailures       bool
+	auditLog            string
+	trace               bool
+	deviceFromGit       bool
+	replaceKeyringLive  bool
+	apiKeyFD            int
+	dashboardBaseURL    string
+
+	readClipboard   func() (string, error)
+	stdinIsTerminal func() bool
 }
 
 func newLoginCmd() *loginCmd {
-	lc := &loginCmd{}
+	lc := &loginCmd{
+		readClipboard: clipboard.Read,
+		stdinIsTerminal: func() bool {
+			return term.IsTerminal(int(os.Stdin.Fd()))
+		},
+	}
 
 	lc.cmd = &cobra.Command{
 		Use:   "login",
@@ -25,6 +74,28 @@ func newLoginCmd() *loginCmd {
 		RunE:  lc.runLoginCmd,
 	}
 	lc.cmd.Flags().BoolVarP(&lc.interactive, "interactive", "i", false, "Run interactive configuration mode if you cannot open a browser")
+	lc.cmd.Flags().BoolVar(&lc.interactiveFallback, "interactive-fallback", true, "Fall back to the browser or interactive login when no API key is provided with --api-key, STRIPE_API_KEY or STRIPE_SECRET_KEY")
+	lc.cmd.Flags().BoolVar(&lc.force, "force", false, "Replace an API key already configured for the project without asking for confirmation, even if it belongs to another account")
+	lc.cmd.Flags().BoolVar(&lc.dryRun, "dry-run", false, "Validate the API key and show the profile that would be configured, without writing it")
+	lc.cmd.Flags().BoolVar(&lc.json, "json", false, "Output the --dry-run result as JSON")
+	lc.cmd.Flags().BoolVar(&lc.outputProfileOnly, "output-profile-only", false, "Print the account and mode of the API key as JSON, without configuring the project")
+	lc.cmd.MarkFlagsMutuallyExclusive("dry-run", "output-profile-only")
+	lc.cmd.Flags().BoolVar(&lc.keyring, "keyring", false, "Store the API key in the system keyring instead of the config file")
+	lc.cmd.Flags().BoolVar(&lc.verify, "verify", false, "Fail without saving the API key if its account can't be retrieved")
+	lc.cmd.Flags().BoolVar(&lc.testMode, "test", false, "Require the API key to be a test mode key")
+	lc.cmd.Flags().BoolVar(&lc.liveMode, "live", false, "Require the API key to be a live mode key")
+	lc.cmd.MarkFlagsMutuallyExclusive("test", "live")
+	lc.cmd.Flags().IntVar(&lc.apiKeyFD, "api-key-fd", -1, "Read the API key to log in with from this file descriptor")
+	lc.cmd.Flags().BoolVar(&lc.fromClipboard, "from-clipboard", false, "Read the API key to log in with from the system clipboard")
+	lc.cmd.Flags().StringVar(&lc.apiKeyCommand, "api-key-command", "", "Run this command and log in with the API key it prints, e.g. to read it from a vault")
+	lc.cmd.Flags().StringVar(&lc.credentialsFile, "credentials-file", "", "Log in with the api_key, and optionally the api_base, device_name and project_name, of this JSON file")
+	lc.cmd.MarkFlagsMutuallyExclusive("api-key-fd", "from-clipboard", "api-key-command", "credentials-file")
+	lc.cmd.Flags().StringVar(&lc.auditLog, "audit-log", "", "Append API key logins to this JSON lines file (default is audit.log next to the config file)")
+	lc.cmd.Flags().BoolVar(&lc.auditFailures, "audit-failures", false, "Also append failed API key logins to the audit log")
+	lc.cmd.Flags().BoolVar(&lc.replaceKeyringLive, "replace-keyring-live", false, "Rotate the live mode key stored in the keyring, keeping the old one unless the new one is verified and stored")
+	lc.cmd.Flags().StringVar(&lc.stripeAccount, "stripe-account", "", "Store this connected account in the project, only sent as the Stripe-Account header when verifying the API key at login and with config doctor --verify")
+	lc.cmd.Flags().BoolVar(&lc.deviceFromGit, "device-from-git", false, "Name the device after your git user name and the hostname, e.g. alice@laptop")
+	lc.cmd.Flags().BoolVar(&lc.trace, "trace", false, "Log the HTTP requests sent to retrieve the account of the API key, with credentials redacted (implies --log-level debug)")
 
 	// Hidden configuration flags, useful for dev/debugging
 	lc.cmd.Flags().StringVar(&lc.dashboardBaseURL, "dashboard-base", stripe.DefaultDashboardBaseURL, "Sets the dashboard base URL")
@@ -38,9 +109,348 @@ func (lc *loginCmd) runLoginCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	var apiKey string
+
+	// The environment is only read when the key isn't given explicitly, so a
+	// stale STRIPE_SECRET_KEY_FILE can't fail the login
+	if !lc.hasAPIKeySource() {
+		var err error
+
+		apiKey, err = getLoginAPIKey(Config.Profile.APIKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	if lc.apiKeyFD >= 0 {
+		if Config.Profile.APIKey != "" {
+			return errors.New("--api-key and --api-key-fd can't be used together")
+		}
+
+		var err error
+
+		apiKey, err = readAPIKeyFromFD(lc.apiKeyFD)
+		if err != nil {
+			return err
+		}
+	}
+
+	if lc.fromClipboard {
+		if Config.Profile.APIKey != "" {
+			return errors.New("--api-key and --from-clipboard can't be used together")
+		}
+
+		var err error
+
+		apiKey, err = readAPIKeyFromClipboard(lc.readClipboard)
+		if err != nil {
+			return err
+		}
+	}
+
+	if lc.apiKeyCommand != "" {
+		if Config.Profile.APIKey != "" {
+			return errors.New("--api-key and --api-key-command can't be used together")
+		}
+
+		var err error
+
+		apiKey, err = readAPIKeyFromCommand(cmd.Context(), lc.apiKeyCommand, apiKeyCommandTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	var credentials *loginCredentials
+
+	if lc.credentialsFile != "" {
+		if Config.Profile.APIKey != "" {
+			return errors.New("--api-key and --credentials-file can't be used together")
+		}
+
+		var err error
+
+		credentials, err = readCredentialsFile(lc.credentialsFile)
+		if err != nil {
+			return err
+		}
+
+		apiKey = credentials.APIKey
+		if credentials.ProjectName != "" {
+			Config.Profile.ProfileName = credentials.ProjectName
+		}
+	}
+
+	var mode string
+
+	switch {
+	case lc.testMode:
+		mode = "test"
+	case lc.liveMode:
+		mode = "live"
+	}
+
+	if apiKey != "" {
+		// Abort in-flight account lookups on Ctrl-C instead of waiting for
+		// them to time out
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// InitConfig defaults the device name to the hostname, let the login
+		// resolve it instead unless it was given with --device-name
+		if !cmd.Flags().Changed("device-name") {
+			Config.Profile.DeviceName = ""
+		}
+
+		fetcher := login.NewHTTPAccountFetcher(&Config.Profile)
+
+		if credentials != nil {
+			fetcher.BaseURL = credentials.APIBase
+			if credentials.DeviceName != "" {
+				Config.Profile.DeviceName = credentials.DeviceName
+			}
+		}
+
+		if lc.trace {
+			log.SetLevel(log.DebugLevel)
+			fetcher.WrapTransport = stripe.WithTrace
+		}
+
+		return login.LoginWithAPIKey(ctx, &Config, apiKey, login.APIKeyLoginOptions{
+			Fetcher:        fetcher,
+			Force:          lc.force,
+			NoInput:        lc.noInput(),
+			DryRun:         lc.dryRun,
+			IdentityOnly:   lc.outputProfileOnly,
+			JSON:           lc.json,
+			Keyring:        lc.keyring,
+			Verify:         lc.verify,
+			Mode:           mode,
+			AuditLog:       lc.auditLog,
+			AuditFailures:  lc.auditFailures,
+			DeviceFromGit:  lc.deviceFromGit,
+			ReplaceLiveKey: lc.replaceKeyringLive,
+			StripeAccount:  lc.stripeAccount,
+		})
+	}
+
+	if lc.outputProfileOnly {
+		return errors.New("--output-profile-only requires an API key, provided with --api-key, STRIPE_API_KEY or STRIPE_SECRET_KEY")
+	}
+
+	if lc.dryRun {
+		return errors.New("--dry-run requires an API key, provided with --api-key, STRIPE_API_KEY or STRIPE_SECRET_KEY")
+	}
+
+	if mode != "" {
+		return errors.New("--test and --live require an API key, provided with --api-key, STRIPE_API_KEY or STRIPE_SECRET_KEY")
+	}
+
+	if !lc.interactiveFallback {
+		return errors.New("no API key provided and interactive login disabled")
+	}
+
 	if lc.interactive {
-		return login.InteractiveLogin(cmd.Context(), &Config)
+		return login.InteractiveLogin(cmd.Context(), &Config, login.APIKeyLoginOptions{
+			Force: lc.force,
+		})
 	}
 
 	return login.Login(cmd.Context(), lc.dashboardBaseURL, &Config)
 }
+
+// hasAPIKeySource returns whether a flag reading the API key from somewhere
+// other than --api-key or the environment is set
+func (lc *loginCmd) hasAPIKeySource() bool {
+	return lc.apiKeyFD >= 0 || lc.fromClipboard || lc.apiKeyCommand != "" || lc.credentialsFile != ""
+}
+
+// getLoginAPIKey returns the API key to log in with non-interactively. In order
+// of precedence: the --api-key flag, STRIPE_API_KEY, STRIPE_SECRET_KEY and the
+// file STRIPE_SECRET_KEY_FILE points to.
+func getLoginAPIKey(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	if key := os.Getenv("STRIPE_API_KEY"); key != "" {
+		return key, nil
+	}
+
+	if key := os.Getenv("STRIPE_SECRET_KEY"); key != "" {
+		return key, nil
+	}
+
+	// Following the Docker secrets convention, the key can be read from a
+	// file instead of being stored in the environment
+	if path := os.Getenv("STRIPE_SECRET_KEY_FILE"); path != "" {
+		return readAPIKeyFromFile(path)
+	}
+
+	return "", nil
+}
+
+// readSecretFile reads the file at path, which must only be readable by its
+// owner
+func readSecretFile(path string) ([]byte, error) {
+	_, err := config.CheckFilePermissions(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+// readAPIKeyFromFile reads the API key from the file at path, which must only
+// be readable by its owner
+func readAPIKeyFromFile(path string) (string, error) {
+	content, err := readSecretFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the API key from STRIPE_SECRET_KEY_FILE: %w", err)
+	}
+
+	apiKey := strings.TrimSpace(string(content))
+	if apiKey == "" {
+		return "", fmt.Errorf("no API key could be read from %s", path)
+	}
+
+	return apiKey, nil
+}
+
+// loginCredentials is the content of the --credentials-file file. Only
+// APIKey is required.
+type loginCredentials struct {
+	APIKey      string `json:"api_key"`
+	APIBase     string `json:"api_base"`
+	DeviceName  string `json:"device_name"`
+	ProjectName string `json:"project_name"`
+}
+
+// readCredentialsFile reads and validates the JSON credentials file at path,
+// which must only be readable by its owner
+func readCredentialsFile(path string) (*loginCredentials, error) {
+	content, err := readSecretFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the credentials file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+
+	credentials := &loginCredentials{}
+	if err := decoder.Decode(credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse the credentials file %s: %w", path, err)
+	}
+
+	if err := validators.APIKey(credentials.APIKey); err != nil {
+		return nil, fmt.Errorf("invalid api_key in the credentials file: %w", err)
+	}
+
+	if credentials.APIBase != "" {
+		if err := stripe.ValidateAPIBaseURL(credentials.APIBase); err != nil {
+			return nil, fmt.Errorf("invalid api_base in the credentials file: %w", err)
+		}
+	}
+
+	credentials.DeviceName = strings.TrimSpace(credentials.DeviceName)
+
+	// Profiles are TOML tables, a dot would nest them
+	if strings.ContainsAny(credentials.ProjectName, ". \t") {
+		return nil, fmt.Errorf("invalid project_name in the credentials file: %q can't contain dots or spaces", credentials.ProjectName)
+	}
+
+	return credentials, nil
+}
+
+// readAPIKeyFromFD reads the API key from the first line of the already open
+// file descriptor fd, which keeps the key out of the process arguments and
+// leaves stdin available for prompts
+func readAPIKeyFromFD(fd int) (string, error) {
+	file := os.NewFile(uintptr(fd), "api-key-fd")
+	if file == nil {
+		return "", fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	defer file.Close()
+
+	apiKey, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read the API key from file descriptor %d: %w", fd, err)
+	}
+
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return "", fmt.Errorf("no API key could be read from file descriptor %d", fd)
+	}
+
+	return apiKey, nil
+}
+
+// readAPIKeyFromClipboard reads and validates the API key copied to the
+// clipboard, without ever printing it
+func readAPIKeyFromClipboard(read func() (string, error)) (string, error) {
+	content, err := read()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the API key from the clipboard: %w", err)
+	}
+
+	apiKey := strings.TrimSpace(content)
+	if apiKey == "" {
+		return "", errors.New("the clipboard is empty, copy your API key and try again")
+	}
+
+	if err := validators.APIKey(apiKey); err != nil {
+		return "", fmt.Errorf("the clipboard doesn't contain a valid API key: %w", err)
+	}
+
+	return apiKey, nil
+}
+
+// readAPIKeyFromCommand runs command and returns the API key it prints on
+// stdout. The key is never logged, but stderr is included in the error when
+// the command fails.
+func readAPIKeyFromCommand(ctx context.Context, command string, timeout time.Duration) (string, error) {
+	args, err := shellquote.Split(command)
+	if err != nil {
+		return "", fmt.Errorf("invalid API key command: %w", err)
+	}
+
+	if len(args) == 0 {
+		return "", errors.New("the API key command is empty")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	keyCmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	keyCmd.Stdout = &stdout
+	keyCmd.Stderr = &stderr
+
+	err = keyCmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("the API key command timed out after %s", timeout)
+	}
+
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			return "", fmt.Errorf("the API key command failed: %w", err)
+		}
+
+		return "", fmt.Errorf("the API key command failed: %w: %s", err, message)
+	}
+
+	apiKey := strings.TrimSpace(stdout.String())
+	if err := validators.APIKey(apiKey); err != nil {
+		return "", fmt.Errorf("the API key command didn't print a valid API key: %w", err)
+	}
+
+	return apiKey, nil
+}
+
+// noInput reports whether login must not prompt: stdin isn't a terminal, or
+// the output is JSON meant to be parsed by a script
+func (lc *loginCmd) noInput() bool {
+	return lc.json || !lc.stdinIsTerminal()
+}
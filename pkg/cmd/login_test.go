@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/login/acct"
+)
+
+func TestLoginInteractiveFallbackDisabled(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY", "")
+
+	lc := newLoginCmd()
+	lc.cmd.SetArgs([]string{"--interactive-fallback=false", "--interactive"})
+	lc.cmd.SilenceUsage = true
+
+	err := lc.cmd.Execute()
+	require.EqualError(t, err, "no API key provided and interactive login disabled")
+}
+
+func TestGetLoginAPIKeyFlag(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "sk_test_from_api_key_env")
+	t.Setenv("STRIPE_SECRET_KEY", "sk_test_from_secret_key_env")
+
+	apiKey, err := getLoginAPIKey("sk_test_from_flag")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_from_flag", apiKey)
+}
+
+func TestGetLoginAPIKeyAPIKeyEnv(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "sk_test_from_api_key_env")
+	t.Setenv("STRIPE_SECRET_KEY", "sk_test_from_secret_key_env")
+
+	apiKey, err := getLoginAPIKey("")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_from_api_key_env", apiKey)
+}
+
+func TestGetLoginAPIKeySecretKeyEnv(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY", "sk_test_from_secret_key_env")
+
+	apiKey, err := getLoginAPIKey("")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_from_secret_key_env", apiKey)
+}
+
+func TestGetLoginAPIKeyNone(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY_FILE", "")
+
+	apiKey, err := getLoginAPIKey("")
+	require.NoError(t, err)
+	require.Equal(t, "", apiKey)
+}
+
+func TestGetLoginAPIKeySecretKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stripe")
+	require.NoError(t, os.WriteFile(path, []byte("sk_test_from_secret_file\n"), 0600))
+
+	t.Setenv("STRIPE_API_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY_FILE", path)
+
+	apiKey, err := getLoginAPIKey("")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_from_secret_file", apiKey)
+}
+
+func TestGetLoginAPIKeySecretKeyFileMissing(t *testing.T) {
+	t.Setenv("STRIPE_API_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY", "")
+	t.Setenv("STRIPE_SECRET_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	_, err := getLoginAPIKey("")
+	require.ErrorContains(t, err, "failed to read the API key from STRIPE_SECRET_KEY_FILE")
+}
+
+func TestReadAPIKeyFromClipboard(t *testing.T) {
+	apiKey, err := readAPIKeyFromClipboard(func() (string, error) {
+		return "  sk_test_1234567890abcd\n", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", apiKey)
+}
+
+func TestReadAPIKeyFromClipboardInvalid(t *testing.T) {
+	_, err := readAPIKeyFromClipboard(func() (string, error) {
+		return "pk_test_1234567890abcd", nil
+	})
+	require.EqualError(t, err, "the clipboard doesn't contain a valid API key: the CLI only supports using a secret or restricted key")
+	require.NotContains(t, err.Error(), "1234567890")
+}
+
+func TestLoginFromClipboardWithAPIKeyFD(t *testing.T) {
+	lc := newLoginCmd()
+	lc.readClipboard = func() (string, error) {
+		return "sk_test_1234567890abcd", nil
+	}
+	lc.cmd.SetArgs([]string{"--from-clipboard", "--api-key-fd", "3"})
+	lc.cmd.SilenceUsage = true
+
+	err := lc.cmd.Execute()
+	require.ErrorContains(t, err, "none of the others can be")
+}
+
+func TestLoginFromClipboardIgnoresSecretKeyFile(t *testing.T) {
+	t.Setenv("STRIPE_SECRET_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	lc := newLoginCmd()
+	lc.readClipboard = func() (string, error) {
+		return "pk_test_1234567890abcd", nil
+	}
+	lc.cmd.SetArgs([]string{"--from-clipboard"})
+	lc.cmd.SilenceUsage = true
+
+	err := lc.cmd.Execute()
+	require.EqualError(t, err, "the clipboard doesn't contain a valid API key: the CLI only supports using a secret or restricted key")
+}
+
+func TestLoginNoInput(t *testing.T) {
+	lc := newLoginCmd()
+	lc.stdinIsTerminal = func() bool { return true }
+	require.False(t, lc.noInput())
+
+	// JSON output is meant for scripts, which can't answer prompts
+	require.NoError(t, lc.cmd.Flags().Set("json", "true"))
+	require.True(t, lc.noInput())
+
+	lc = newLoginCmd()
+	lc.stdinIsTerminal = func() bool { return false }
+	require.True(t, lc.noInput())
+}
+
+func writeCredentialsFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "creds.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func TestReadCredentialsFile(t *testing.T) {
+	path := writeCredentialsFile(t, `{"api_key": "sk_test_1234567890abcd", "api_base": "http://127.0.0.1:12111", "device_name": " ci-runner ", "project_name": "ci"}`)
+
+	credentials, err := readCredentialsFile(path)
+	require.NoError(t, err)
+	require.Equal(t, &loginCredentials{
+		APIKey:      "sk_test_1234567890abcd",
+		APIBase:     "http://127.0.0.1:12111",
+		DeviceName:  "ci-runner",
+		ProjectName: "ci",
+	}, credentials)
+}
+
+func TestReadCredentialsFileInvalid(t *testing.T) {
+	for content, expected := range map[string]string{
+		`{"api_key": "pk_test_1234567890abcd"}`:                                 "invalid api_key in the credentials file",
+		`{"api_key": "sk_test_1234567890abcd", "api_base": "https://evil.com"}`: "invalid api_base in the credentials file",
+		`{"api_key": "sk_test_1234567890abcd", "project_name": "ci.staging"}`:   "invalid project_name in the credentials file",
+		`{"api_key": "sk_test_1234567890abcd", "secret": "sk_test_1234567890"}`: "unknown field",
+		`not json`: "failed to parse the credentials file",
+	} {
+		_, err := readCredentialsFile(writeCredentialsFile(t, content))
+		require.ErrorContains(t, err, expected, content)
+	}
+}
+
+func TestLoginCredentialsFile(t *testing.T) {
+	if os.Getenv("BE_TestLoginCredentialsFile") == "1" {
+		_, err := executeCommand(rootCmd, "login", "--credentials-file", os.Getenv("CREDENTIALS_FILE"), "--verify")
+		require.NoError(t, err)
+		return
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/account", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&acct.Account{ID: "acct_ci"})
+	}))
+	defer ts.Close()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	credentialsFile := writeCredentialsFile(t, `{"api_key": "sk_test_1234567890abcd", "api_base": "`+ts.URL+`", "device_name": "ci-runner", "project_name": "ci"}`)
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestLoginCredentialsFile")
+	cmd.Env = append(os.Environ(), "BE_TestLoginCredentialsFile=1", "STRIPE_CONFIG_FILE="+profilesFile, "CREDENTIALS_FILE="+credentialsFile,
+		"STRIPE_PROJECT_NAME=", "STRIPE_API_KEY=", "STRIPE_SECRET_KEY=", "STRIPE_DEVICE_NAME=")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "sk_test_1234567890abcd", v.GetString("ci.test_mode_api_key"))
+	require.Equal(t, "ci-runner", v.GetString("ci.device_name"))
+	require.Equal(t, "acct_ci", v.GetString("ci.account_id"))
+	require.False(t, v.IsSet("default"))
+}
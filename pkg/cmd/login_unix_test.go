@@ -0,0 +1,73 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// pipeFD returns a duplicate of the read end of a pipe holding content, so
+// that readAPIKeyFromFD can close it without closing the *os.File
+func pipeFD(t *testing.T, content string) int {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = w.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	fd, err := unix.Dup(int(r.Fd()))
+	require.NoError(t, err)
+
+	return fd
+}
+
+func TestReadAPIKeyFromFD(t *testing.T) {
+	apiKey, err := readAPIKeyFromFD(pipeFD(t, "  sk_test_1234567890\nignored\n"))
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890", apiKey)
+}
+
+func TestReadAPIKeyFromFDEmpty(t *testing.T) {
+	fd := pipeFD(t, "\n")
+
+	_, err := readAPIKeyFromFD(fd)
+	require.EqualError(t, err, fmt.Sprintf("no API key could be read from file descriptor %d", fd))
+}
+
+func TestReadAPIKeyFromCommand(t *testing.T) {
+	apiKey, err := readAPIKeyFromCommand(context.Background(), "echo '  sk_test_1234567890abcd  '", time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", apiKey)
+}
+
+func TestReadAPIKeyFromCommandFails(t *testing.T) {
+	_, err := readAPIKeyFromCommand(context.Background(), "sh -c 'echo permission denied >&2; exit 2'", time.Second)
+	require.EqualError(t, err, "the API key command failed: exit status 2: permission denied")
+}
+
+func TestReadAPIKeyFromCommandTimeout(t *testing.T) {
+	_, err := readAPIKeyFromCommand(context.Background(), "sleep 5", 50*time.Millisecond)
+	require.EqualError(t, err, "the API key command timed out after 50ms")
+}
+
+func TestReadAPIKeyFromFileInsecure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stripe")
+	require.NoError(t, os.WriteFile(path, []byte("sk_test_from_secret_file\n"), 0600))
+	require.NoError(t, os.Chmod(path, 0644))
+
+	_, err := readAPIKeyFromFile(path)
+	require.EqualError(t, err, fmt.Sprintf("failed to read the API key from STRIPE_SECRET_KEY_FILE: %s has insecure permissions 0644, run `chmod 600 %s`", path, path))
+}
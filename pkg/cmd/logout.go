@@ -8,8 +8,10 @@ import (
 )
 
 type logoutCmd struct {
-	cmd *cobra.Command
-	all bool
+	cmd      *cobra.Command
+	all      bool
+	testMode bool
+	liveMode bool
 }
 
 func newLogoutCmd() *logoutCmd {
@@ -24,6 +26,9 @@ func newLogoutCmd() *logoutCmd {
 	}
 
 	lc.cmd.Flags().BoolVarP(&lc.all, "all", "a", false, "Clear credentials for all projects you are currently logged into.")
+	lc.cmd.Flags().BoolVar(&lc.testMode, "test", false, "Only clear the test mode credentials of the project")
+	lc.cmd.Flags().BoolVar(&lc.liveMode, "live", false, "Only clear the live mode credentials of the project")
+	lc.cmd.MarkFlagsMutuallyExclusive("all", "test", "live")
 
 	return lc
 }
@@ -33,5 +38,9 @@ func (lc *logoutCmd) runLogoutCmd(cmd *cobra.Command, args []string) error {
 		return logout.All(&Config)
 	}
 
+	if lc.testMode || lc.liveMode {
+		return logout.Mode(&Config, lc.liveMode)
+	}
+
 	return logout.Logout(&Config)
 }
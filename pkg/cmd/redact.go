@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+// apiKeyPattern matches secret and restricted API keys long enough to be
+// redacted with config.RedactAPIKey
+var apiKeyPattern = regexp.MustCompile(`\b(sk|rk)_(test|live)_[0-9a-zA-Z]{4,}`)
+
+// redactAPIKeys replaces every API key found in s with its redacted form so
+// that error messages echoing the command line don't leak secrets
+func redactAPIKeys(s string) string {
+	return apiKeyPattern.ReplaceAllStringFunc(s, config.RedactAPIKey)
+}
+
+// redactFlagError is installed as the flag error handler of the root command:
+// pflag errors quote the invalid value, which may be an API key
+func redactFlagError(cmd *cobra.Command, err error) error {
+	redacted := redactAPIKeys(err.Error())
+	if redacted == err.Error() {
+		return err
+	}
+
+	return errors.New(redacted)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactAPIKeys(t *testing.T) {
+	require.Equal(t,
+		`invalid argument "sk_live_**********abcd" for "--limit" flag`,
+		redactAPIKeys(`invalid argument "sk_live_1234567890abcd" for "--limit" flag`))
+	require.Equal(t, "rk_test_******wxyz and pk_test_1234567890", redactAPIKeys("rk_test_123456wxyz and pk_test_1234567890"))
+	require.Equal(t, "sk_test_12", redactAPIKeys("sk_test_12"))
+}
+
+func TestFlagErrorRedactsAPIKey(t *testing.T) {
+	output, err := executeCommand(rootCmd, "login", "--api-key-fd", "sk_live_1234567890abcd")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sk_live_**********abcd")
+	require.NotContains(t, err.Error(), "1234567890")
+	require.NotContains(t, output, "1234567890")
+}
@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	configcmd "github.com/stripe/stripe-cli/pkg/cmd/config"
 	"github.com/stripe/stripe-cli/pkg/cmd/resource"
 	"github.com/stripe/stripe-cli/pkg/config"
 	"github.com/stripe/stripe-cli/pkg/login"
@@ -95,7 +97,7 @@ func showSuggestion() {
 
 	fmt.Println(fmt.Sprintf("Unknown command \"%s\" for \"%s\".%s"+
 		"ee \"stripe --help\" for a list of available commands.",
-		os.Args[1], rootCmd.CommandPath(), suggStr))
+		redactAPIKeys(os.Args[1]), rootCmd.CommandPath(), suggStr))
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -113,10 +115,18 @@ func Execute(ctx context.Context) {
 		projectNameFlag := rootCmd.Flag("project-name").Value.String()
 
 		switch {
+		case errors.Is(err, configcmd.ErrReported):
+			// Nothing to print, the command's output already describes the failure
 		case requests.IsAPIKeyExpiredError(err):
 			fmt.Fprintln(os.Stderr, "The API key provided has expired. Obtain a new key from the Dashboard or run `stripe login` and try again.")
 		case isLoginRequiredError && projectNameFlag != "default":
-			fmt.Printf("You provided the project name \"%[1]s\" (either via the \"--project-name\" flag or the \"STRIPE_PROJECT_NAME\" environment variable), but no config for that project was found.\nPlease run `stripe login --project-name=%[1]s` to enable commands for this project.\n", projectNameFlag)
+			fmt.Printf("You provided the project name \"%[1]s\" (either via the \"--project-name\" flag or the \"STRIPE_PROJECT_NAME\" environment variable), but no config for that project was found.\n", projectNameFlag)
+
+			if suggestion := Config.SuggestProfileName(projectNameFlag); suggestion != "" && !Config.ProfileExists(projectNameFlag) {
+				fmt.Printf("Did you mean \"%s\"?\n", suggestion)
+			}
+
+			fmt.Printf("Please run `stripe login --project-name=%s` to enable commands for this project.\n", projectNameFlag)
 		case isLoginRequiredError:
 			// capitalize first letter of error because linter
 			errRunes := []rune(errString)
@@ -134,7 +144,7 @@ func Execute(ctx context.Context) {
 			showSuggestion()
 
 		default:
-			fmt.Println(err)
+			fmt.Println(redactAPIKeys(errString))
 		}
 
 		os.Exit(1)
@@ -147,17 +157,34 @@ func Execute(ctx context.Context) {
 	}
 }
 
-var keysToReBind []string
+// keysToReBind maps flags to the environment variable bound to them
+var keysToReBind = map[string]string{}
 
-// ReBindKeys applies the value found in viper config to the cobra flag when viper has a value (possibly from env)
+// ReBindKeys applies the value of the environment variable bound to a flag
+// when the flag isn't set. Values found in the profiles file are ignored,
+// older versions persisted flags there by mistake.
 func ReBindKeys() {
-	for _, k := range keysToReBind {
-		if viper.IsSet(k) {
-			rootCmd.Flags().Set(k, viper.GetString(k))
+	for k, envKey := range keysToReBind {
+		if value := os.Getenv(envKey); value != "" && !rootCmd.PersistentFlags().Changed(k) {
+			rootCmd.Flags().Set(k, value)
 		}
 	}
 }
 
+// UseDefaultProfile selects the profile set with `stripe config use` when no
+// project name is given with --project-name or STRIPE_PROJECT_NAME
+func UseDefaultProfile() {
+	// ReBindKeys sets the flag from STRIPE_PROJECT_NAME. viper.IsSet would
+	// also be true for a project-name key in the profiles file.
+	if rootCmd.PersistentFlags().Changed("project-name") {
+		return
+	}
+
+	if profileName := Config.GetDefaultProfile(); profileName != "" {
+		Config.Profile.ProfileName = profileName
+	}
+}
+
 // wraps viper's bindEnv and ensures we write values back to the Config
 // value precedence is:
 // 1. flag
@@ -166,19 +193,21 @@ func ReBindKeys() {
 func bindEnv(key, envKey string) {
 	viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(key))
 	viper.BindEnv(key, envKey)
-	keysToReBind = append(keysToReBind, key)
+	keysToReBind[key] = envKey
 }
 
 func init() {
-	cobra.OnInitialize(Config.InitConfig, ReBindKeys)
+	cobra.OnInitialize(Config.InitConfig, ReBindKeys, UseDefaultProfile)
 
 	rootCmd.PersistentFlags().StringVar(&Config.Profile.APIKey, "api-key", "", "Your API key to use for the command")
 	rootCmd.PersistentFlags().StringVar(&Config.Color, "color", "", "turn on/off color output (on, off, auto)")
-	rootCmd.PersistentFlags().StringVar(&Config.ProfilesFile, "config", "", "config file (default is $HOME/.config/stripe/config.toml)")
+	rootCmd.PersistentFlags().StringVar(&Config.ProfilesFile, "config", "", "config file (default is $STRIPE_CONFIG_FILE, then $HOME/.config/stripe/config.toml)")
 	rootCmd.PersistentFlags().StringVar(&Config.Profile.DeviceName, "device-name", "", "device name")
 	rootCmd.PersistentFlags().StringVar(&Config.LogLevel, "log-level", "info", "log level (debug, info, trace, warn, error)")
 	rootCmd.PersistentFlags().StringVarP(&Config.Profile.ProfileName, "project-name", "p", "default", "the project name to read from for config")
+	rootCmd.PersistentFlags().Var(newTimeoutValue(&stripe.DefaultTimeout), "timeout", "time limit for requests to the Stripe API and for forwarding events with listen, e.g. 10s")
 	rootCmd.Flags().BoolP("version", "v", false, "Get the version of the Stripe CLI")
+	rootCmd.SetFlagErrorFunc(redactFlagError)
 
 	// tell viper to monitor the following flags:
 	// they will be available via viper.get(KEY), but not mapped back to the Config (by default; see below)
@@ -114,6 +114,62 @@ func TestReadProjectFlagHasPrecedence(t *testing.T) {
 	require.Equal(t, Config.Profile.ProfileName, "from-flag")
 }
 
+func TestReadProjectFromDefaultProfile(t *testing.T) {
+	// Run this test in a subprocess since side effects from other tests interfere with this
+	if os.Getenv("BE_TestReadProjectFromDefaultProfile") == "1" {
+		executeCommand(rootCmd, "version")
+
+		require.Equal(t, "staging", Config.Profile.ProfileName)
+		return
+	}
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := "default_profile = 'staging'\n\n[staging]\ntest_mode_api_key = 'sk_test_123'\n"
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestReadProjectFromDefaultProfile")
+	cmd.Env = append(os.Environ(), "BE_TestReadProjectFromDefaultProfile=1", "STRIPE_CONFIG_FILE="+profilesFile, "STRIPE_PROJECT_NAME=")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestReadProjectFlagOverridesDefaultProfile(t *testing.T) {
+	if os.Getenv("BE_TestReadProjectFlagOverridesDefaultProfile") == "1" {
+		executeCommand(rootCmd, "version", "--project-name", "from-flag")
+
+		require.Equal(t, "from-flag", Config.Profile.ProfileName)
+		return
+	}
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := "default_profile = 'staging'\n\n[staging]\ntest_mode_api_key = 'sk_test_123'\n"
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestReadProjectFlagOverridesDefaultProfile")
+	cmd.Env = append(os.Environ(), "BE_TestReadProjectFlagOverridesDefaultProfile=1", "STRIPE_CONFIG_FILE="+profilesFile)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestStoredProjectNameIgnoredForDefaultProfile(t *testing.T) {
+	if os.Getenv("BE_TestStoredProjectNameIgnoredForDefaultProfile") == "1" {
+		executeCommand(rootCmd, "version")
+
+		require.Equal(t, "staging", Config.Profile.ProfileName)
+		return
+	}
+
+	// older versions persisted the project-name flag in the profiles file
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := "default_profile = 'staging'\nproject-name = 'default'\n\n[staging]\ntest_mode_api_key = 'sk_test_123'\n"
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestStoredProjectNameIgnoredForDefaultProfile")
+	cmd.Env = append(os.Environ(), "BE_TestStoredProjectNameIgnoredForDefaultProfile=1", "STRIPE_CONFIG_FILE="+profilesFile, "STRIPE_PROJECT_NAME=")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
 func TestV2BillingOverrides(t *testing.T) {
 	Execute(context.Background())
 
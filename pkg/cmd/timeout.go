@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// timeoutValue is a pflag.Value for durations that must be positive, so that
+// invalid timeouts are rejected while parsing flags. A bare number is a number
+// of seconds, as listen's former --timeout flag took.
+type timeoutValue time.Duration
+
+func newTimeoutValue(p *time.Duration) *timeoutValue {
+	return (*timeoutValue)(p)
+}
+
+func (t *timeoutValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if seconds, convErr := strconv.ParseInt(s, 10, 64); convErr == nil {
+		d, err = time.Duration(seconds)*time.Second, nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if d <= 0 {
+		return errors.New("must be greater than zero")
+	}
+
+	*t = timeoutValue(d)
+
+	return nil
+}
+
+func (t *timeoutValue) Type() string {
+	return "duration"
+}
+
+func (t *timeoutValue) String() string {
+	return time.Duration(*t).String()
+}
+
+// forwardTimeoutSeconds converts the --timeout flag to the whole seconds
+// events are forwarded with, rounding up so that short timeouts don't become
+// zero, which disables the limit
+func forwardTimeoutSeconds(timeout time.Duration) int64 {
+	return int64((timeout + time.Second - 1) / time.Second)
+}
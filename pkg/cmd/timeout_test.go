@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutValue(t *testing.T) {
+	timeout := 30 * time.Second
+	value := newTimeoutValue(&timeout)
+
+	require.NoError(t, value.Set("5s"))
+	require.Equal(t, 5*time.Second, timeout)
+	require.Equal(t, "5s", value.String())
+
+	// a bare number is a number of seconds
+	require.NoError(t, value.Set("60"))
+	require.Equal(t, time.Minute, timeout)
+
+	require.NoError(t, value.Set("5s"))
+	require.EqualError(t, value.Set("0"), "must be greater than zero")
+	require.EqualError(t, value.Set("0s"), "must be greater than zero")
+	require.EqualError(t, value.Set("-1s"), "must be greater than zero")
+	require.Error(t, value.Set("soon"))
+	require.Equal(t, 5*time.Second, timeout)
+}
+
+func TestTimeoutFlagRejectsZero(t *testing.T) {
+	_, err := executeCommand(rootCmd, "version", "--timeout", "0s")
+	require.EqualError(t, err, `invalid argument "0s" for "--timeout" flag: must be greater than zero`)
+}
+
+func TestForwardTimeoutSeconds(t *testing.T) {
+	require.Equal(t, int64(30), forwardTimeoutSeconds(30*time.Second))
+	require.Equal(t, int64(2), forwardTimeoutSeconds(1500*time.Millisecond))
+	require.Equal(t, int64(1), forwardTimeoutSeconds(10*time.Millisecond))
+}
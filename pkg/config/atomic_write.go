@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// renameFile moves the temporary profiles file into place, it is swapped in
+// tests to simulate failures
+var renameFile = os.Rename
+
+// writeConfigAtomic writes the configuration held by v to its config file
+// with writeFileAtomic, so that a failed or interrupted write leaves the
+// existing profiles file untouched.
+func writeConfigAtomic(v *viper.Viper) error {
+	setSchemaVersion(v)
+
+	profilesFile := v.ConfigFileUsed()
+	if profilesFile == "" {
+		return v.WriteConfig()
+	}
+
+	return writeFileAtomic(profilesFile, func(tmpName string) error {
+		err := v.WriteConfigAs(tmpName)
+		if err == nil && filepath.Ext(profilesFile) == ".toml" {
+			err = restoreConfigComments(profilesFile, tmpName)
+		}
+
+		return err
+	})
+}
+
+// writeFileAtomic replaces the file at path by calling write on a temporary
+// file in the same directory, which is then renamed into place. The
+// temporary file is created with 0600 permissions and removed on failure.
+// When path is a symlink, the file it points to is replaced instead, so the
+// link is kept.
+func writeFileAtomic(path string, write func(tmpName string) error) error {
+	err := makePath(path)
+	if err != nil {
+		return err
+	}
+
+	// A missing or dangling path is written as is
+	target := path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		target = resolved
+	}
+
+	// Keep the extension of path, which the target may lack, so viper encodes
+	// the temporary file in the same format
+	ext := filepath.Ext(path)
+	pattern := "." + strings.TrimSuffix(filepath.Base(path), ext) + "-*" + ext
+
+	// os.CreateTemp creates the file with 0600 permissions, which are kept
+	// when writing to an existing file
+	tmp, err := os.CreateTemp(filepath.Dir(target), pattern)
+	if err != nil {
+		return err
+	}
+
+	tmpName := tmp.Name()
+	tmp.Close()
+
+	err = write(tmpName)
+	if err == nil {
+		err = renameFile(tmpName, target)
+	}
+
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	return nil
+}
+
+// restoreConfigComments copies the comments of the profiles file over to the
+// newly written tmpName file
+func restoreConfigComments(profilesFile, tmpName string) error {
+	comments := readConfigComments(profilesFile)
+	if comments.empty() {
+		return nil
+	}
+
+	content, err := os.ReadFile(tmpName)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(tmpName, comments.apply(content), 0600)
+}
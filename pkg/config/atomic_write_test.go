@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteConfigAtomicKeepsSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on Windows")
+	}
+
+	// the target lives elsewhere, e.g. in a dotfiles repository, without the
+	// extension of the link
+	target := filepath.Join(t.TempDir(), "stripe-config")
+	require.NoError(t, os.WriteFile(target, []byte("# managed by dotfiles\n[default]\ndevice_name = 'st-testing'\n"), 0600))
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.Symlink(target, profilesFile))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	v.Set("default.color", "off")
+	require.NoError(t, writeConfigAtomic(v))
+
+	info, err := os.Lstat(profilesFile)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "# managed by dotfiles")
+	require.Contains(t, string(content), "color = 'off'")
+
+	// no temporary file is left next to the link or the target
+	for _, dir := range []string{filepath.Dir(profilesFile), filepath.Dir(target)} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	}
+}
+
+func TestWriteConfigAtomicDanglingSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on Windows")
+	}
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.Symlink(filepath.Join(t.TempDir(), "missing"), profilesFile))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	v.Set("default.device_name", "st-testing")
+	require.NoError(t, writeConfigAtomic(v))
+
+	written := viper.New()
+	written.SetConfigFile(profilesFile)
+	require.NoError(t, written.ReadInConfig())
+	require.Equal(t, "st-testing", written.GetString("default.device_name"))
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// BackupProfilesFile copies the profiles file to the backup file at path,
+// which is only readable by its owner. Keys stored in the keyring are not
+// part of the backup.
+func (c *Config) BackupProfilesFile(path string) error {
+	unlock, lockErr := lockProfilesFile(c.ProfilesFile)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(c.ProfilesFile)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, func(tmpName string) error {
+		return os.WriteFile(tmpName, content, 0600)
+	})
+}
+
+// RestoreProfilesFile replaces the profiles file by the backup file at path.
+// The backup must only be readable by its owner and must parse, otherwise the
+// profiles file is left untouched.
+func (c *Config) RestoreProfilesFile(path string) error {
+	_, err := CheckFilePermissions(path)
+	if errors.Is(err, ErrInsecurePermissions) {
+		return fmt.Errorf("backup file %w", err)
+	} else if err != nil {
+		return err
+	}
+
+	// Backups are often named after the profiles file with a suffix, e.g.
+	// config.toml.bak, so the format can't be told from the extension
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+
+	err = v.ReadInConfig()
+	if err != nil {
+		return fmt.Errorf("backup file %s can't be parsed: %w", path, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	unlock, lockErr := lockProfilesFile(c.ProfilesFile)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	err = writeFileAtomic(c.ProfilesFile, func(tmpName string) error {
+		return os.WriteFile(tmpName, content, 0600)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Reload the restored profiles
+	viper.ReadInConfig()
+
+	return nil
+}
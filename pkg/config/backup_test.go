@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBackupConfig(t *testing.T) *Config {
+	t.Helper()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[default]\ndevice_name = 'st-testing'\n"), 0600))
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	return c
+}
+
+func TestBackupAndRestoreProfilesFile(t *testing.T) {
+	c := setupBackupConfig(t)
+	original := helperLoadBytes(t, c.ProfilesFile)
+
+	backupFile := filepath.Join(t.TempDir(), "backups", "config.toml")
+	require.NoError(t, c.BackupProfilesFile(backupFile))
+	require.Equal(t, original, helperLoadBytes(t, backupFile))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(backupFile)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	require.NoError(t, os.WriteFile(c.ProfilesFile, []byte("[default]\ndevice_name = 'changed'\n"), 0600))
+
+	require.NoError(t, c.RestoreProfilesFile(backupFile))
+	require.Equal(t, original, helperLoadBytes(t, c.ProfilesFile))
+	require.Equal(t, "st-testing", viper.GetString("default.device_name"))
+}
+
+func TestRestoreProfilesFileBackupSuffix(t *testing.T) {
+	c := setupBackupConfig(t)
+	original := helperLoadBytes(t, c.ProfilesFile)
+
+	backupFile := c.ProfilesFile + ".bak"
+	require.NoError(t, c.BackupProfilesFile(backupFile))
+	require.NoError(t, os.WriteFile(c.ProfilesFile, []byte("[default]\ndevice_name = 'changed'\n"), 0600))
+
+	require.NoError(t, c.RestoreProfilesFile(backupFile))
+	require.Equal(t, original, helperLoadBytes(t, c.ProfilesFile))
+	require.Equal(t, "st-testing", viper.GetString("default.device_name"))
+}
+
+func TestRestoreProfilesFileInsecureBackup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions are not supported on Windows")
+	}
+
+	c := setupBackupConfig(t)
+	original := helperLoadBytes(t, c.ProfilesFile)
+
+	backupFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(backupFile, []byte("[default]\ndevice_name = 'other'\n"), 0600))
+	require.NoError(t, os.Chmod(backupFile, 0644))
+
+	err := c.RestoreProfilesFile(backupFile)
+	require.EqualError(t, err, "backup file "+backupFile+" has insecure permissions 0644, run `chmod 600 "+backupFile+"`")
+	require.Equal(t, original, helperLoadBytes(t, c.ProfilesFile))
+}
+
+func TestRestoreProfilesFileUnparseableBackup(t *testing.T) {
+	c := setupBackupConfig(t)
+	original := helperLoadBytes(t, c.ProfilesFile)
+
+	backupFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(backupFile, []byte("[default\n"), 0600))
+
+	err := c.RestoreProfilesFile(backupFile)
+	require.ErrorContains(t, err, "backup file "+backupFile+" can't be parsed")
+	require.Equal(t, original, helperLoadBytes(t, c.ProfilesFile))
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+)
+
+// configComments holds the comments of a TOML profiles file that are kept when
+// viper rewrites it, since viper drops comments when writing configs
+type configComments struct {
+	// header holds the comments at the top of the file
+	header []string
+
+	// tables holds the comments directly preceding each table header, keyed
+	// by the normalized table header
+	tables map[string][]string
+}
+
+// readConfigComments collects the comments of the TOML file at path. Comment
+// blocks directly above a table header are kept with that table, and the
+// other comments at the top of the file are kept as the file header.
+func readConfigComments(path string) configComments {
+	comments := configComments{tables: make(map[string][]string)}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return comments
+	}
+
+	var block []string
+	inHeader := true
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "#"):
+			block = append(block, line)
+		case line == "":
+			if inHeader && len(block) > 0 {
+				comments.header = append(comments.header, block...)
+			}
+			block = nil
+		default:
+			if isTableHeader(line) && len(block) > 0 {
+				comments.tables[normalizeTableHeader(line)] = block
+			} else if inHeader {
+				comments.header = append(comments.header, block...)
+			}
+
+			inHeader = false
+			block = nil
+		}
+	}
+
+	return comments
+}
+
+// empty returns whether there are no comments to restore
+func (cc configComments) empty() bool {
+	return len(cc.header) == 0 && len(cc.tables) == 0
+}
+
+// apply inserts the comments back into content, a TOML file written by viper.
+// Comments of tables that no longer exist are dropped.
+func (cc configComments) apply(content []byte) []byte {
+	var out bytes.Buffer
+
+	if len(cc.header) > 0 {
+		out.WriteString(strings.Join(cc.header, "\n"))
+		out.WriteString("\n\n")
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if trimmed := strings.TrimSpace(line); isTableHeader(trimmed) {
+			for _, comment := range cc.tables[normalizeTableHeader(trimmed)] {
+				out.WriteString(comment)
+				out.WriteString("\n")
+			}
+		}
+
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+
+	return out.Bytes()
+}
+
+func isTableHeader(line string) bool {
+	return strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "[[")
+}
+
+// normalizeTableHeader strips trailing comments, whitespace and case from a
+// table header, since viper lowercases keys and rewrites headers
+func normalizeTableHeader(line string) string {
+	if i := strings.Index(line, "]"); i >= 0 {
+		line = line[1:i]
+	}
+
+	fields := strings.Split(line, ".")
+	for i, field := range fields {
+		fields[i] = strings.Trim(strings.TrimSpace(field), `"'`)
+	}
+
+	return strings.ToLower(strings.Join(fields, "."))
+}
@@ -2,9 +2,12 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,6 +31,10 @@ const ColorOff = "off"
 // ColorAuto represents the auto-state for colors
 const ColorAuto = "auto"
 
+// DefaultProfileKey is the config field holding the profile set with
+// `stripe config use`
+const DefaultProfileKey = "default_profile"
+
 // IConfig allows us to add more implementations, such as ones for unit tests
 type IConfig interface {
 	GetProfile() *Profile
@@ -81,6 +88,36 @@ func (c *Config) GetConfigFolder(xdgPath string) string {
 	return stripeConfigPath
 }
 
+// ResolveProfilesFile returns the absolute path of the profiles file the CLI
+// uses and whether it exists. In order of precedence, it's the --config flag,
+// STRIPE_CONFIG_FILE or config.toml in the config folder.
+func (c *Config) ResolveProfilesFile() (string, bool, error) {
+	profilesFile := c.ProfilesFile
+	if profilesFile == "" {
+		profilesFile = os.Getenv("STRIPE_CONFIG_FILE")
+	}
+
+	if profilesFile == "" {
+		profilesFile = filepath.Join(c.GetConfigFolder(os.Getenv("XDG_CONFIG_HOME")), "config.toml")
+	}
+
+	profilesFile, err := filepath.Abs(profilesFile)
+	if err != nil {
+		return "", false, err
+	}
+
+	_, err = os.Stat(profilesFile)
+	if os.IsNotExist(err) {
+		return profilesFile, false, nil
+	}
+
+	if err != nil {
+		return profilesFile, false, err
+	}
+
+	return profilesFile, true, nil
+}
+
 // InitConfig reads in profiles file and ENV variables if set.
 func (c *Config) InitConfig() {
 	logFormatter := &prefixed.TextFormatter{
@@ -106,21 +143,27 @@ func (c *Config) InitConfig() {
 		log.Fatalf("Unrecognized log level value: %s. Expected one of debug, info, warn, error.", c.LogLevel)
 	}
 
-	if c.ProfilesFile != "" {
-		viper.SetConfigFile(c.ProfilesFile)
-	} else {
-		configFolder := c.GetConfigFolder(os.Getenv("XDG_CONFIG_HOME"))
-		configFile := filepath.Join(configFolder, "config.toml")
-		c.ProfilesFile = configFile
+	isDefault := c.ProfilesFile == "" && os.Getenv("STRIPE_CONFIG_FILE") == ""
+
+	configFile, exists, err := c.ResolveProfilesFile()
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+
+	c.ProfilesFile = configFile
+	viper.SetConfigFile(configFile)
+
+	if isDefault {
 		viper.SetConfigType("toml")
-		viper.SetConfigFile(configFile)
 		viper.SetConfigPermissions(os.FileMode(0600))
 
 		// Try to change permissions manually, because we used to create files
 		// with default permissions (0644)
-		err := os.Chmod(configFile, os.FileMode(0600))
-		if err != nil && !os.IsNotExist(err) {
-			log.Fatalf("%s", err)
+		if exists {
+			err = os.Chmod(configFile, os.FileMode(0600))
+			if err != nil {
+				log.Fatalf("%s", err)
+			}
 		}
 	}
 
@@ -130,6 +173,8 @@ func (c *Config) InitConfig() {
 			"prefix": "config.Config.InitConfig",
 			"path":   viper.ConfigFileUsed(),
 		}).Debug("Using profiles file")
+
+		warnNewerSchemaVersion(viper.GetViper())
 	}
 
 	if os.Getenv("STRIPE_CLI_CANARY") == "true" {
@@ -191,7 +236,7 @@ func (c *Config) EditConfig() error {
 func (c *Config) PrintConfig() error {
 	profileName := c.Profile.ProfileName
 
-	if profileName == "default" {
+	if profileName == DefaultProfileName {
 		configFile, err := os.ReadFile(c.ProfilesFile)
 		if err != nil {
 			return err
@@ -202,9 +247,17 @@ func (c *Config) PrintConfig() error {
 		configs := viper.GetStringMapString(profileName)
 
 		if len(configs) > 0 {
+			fields := make([]string, 0, len(configs))
+			for field := range configs {
+				fields = append(fields, field)
+			}
+
+			// Sort the fields so that the output can be diffed
+			sort.Strings(fields)
+
 			fmt.Printf("[%s]\n", profileName)
-			for field, value := range configs {
-				fmt.Printf("  %s=%s\n", field, value)
+			for _, field := range fields {
+				fmt.Printf("  %s=%s\n", field, configs[field])
 			}
 		}
 	}
@@ -223,8 +276,16 @@ func (c *Config) GetInstalledPlugins() []string {
 // RemoveProfile removes the profile whose name matches the provided
 // profileName from the config file.
 func (c *Config) RemoveProfile(profileName string) error {
-	runtimeViper := viper.GetViper()
-	var err error
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	runtimeViper, err := readProfilesFile()
+	if err != nil {
+		return err
+	}
 
 	for field, value := range runtimeViper.AllSettings() {
 		if isProfile(value) && field == profileName {
@@ -237,13 +298,29 @@ func (c *Config) RemoveProfile(profileName string) error {
 		}
 	}
 
+	// Don't leave the active profile pointing at the removed one
+	if runtimeViper.GetString(DefaultProfileKey) == profileName {
+		runtimeViper, err = removeKey(runtimeViper, DefaultProfileKey)
+		if err != nil {
+			return err
+		}
+	}
+
 	return syncConfig(runtimeViper)
 }
 
 // RemoveAllProfiles removes all the profiles from the config file.
 func (c *Config) RemoveAllProfiles() error {
-	runtimeViper := viper.GetViper()
-	var err error
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	runtimeViper, err := readProfilesFile()
+	if err != nil {
+		return err
+	}
 
 	for field, value := range runtimeViper.AllSettings() {
 		if isProfile(value) {
@@ -256,9 +333,134 @@ func (c *Config) RemoveAllProfiles() error {
 		}
 	}
 
+	if runtimeViper.IsSet(DefaultProfileKey) {
+		runtimeViper, err = removeKey(runtimeViper, DefaultProfileKey)
+		if err != nil {
+			return err
+		}
+	}
+
 	return syncConfig(runtimeViper)
 }
 
+// RenameProfile renames the profile oldName to newName, carrying over all of
+// its fields and keyring items. It fails if newName already exists, unless
+// force is set.
+func (c *Config) RenameProfile(oldName, newName string, force bool) error {
+	if oldName == newName {
+		return fmt.Errorf("the profile is already named %s", newName)
+	}
+
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	configMap := v.AllSettings()
+
+	profile, ok := configMap[oldName]
+	if !ok || !isProfile(profile) {
+		return profileNotFoundError(v, oldName)
+	}
+
+	if existing, ok := configMap[newName]; ok && isProfile(existing) && !force {
+		return fmt.Errorf("profile %s already exists, use --force to replace it", newName)
+	}
+
+	configMap[newName] = profile
+	delete(configMap, oldName)
+
+	// Keep the active profile selected with `stripe config use`
+	if configMap[DefaultProfileKey] == oldName {
+		configMap[DefaultProfileKey] = newName
+	}
+
+	runtimeViper, err := newViperFromSettings(configMap)
+	if err != nil {
+		return err
+	}
+
+	err = syncConfig(runtimeViper)
+	if err != nil {
+		return err
+	}
+
+	// Only touch the keyring once the profiles file has been written, and fail
+	// open to avoid blocking the rename when the keyring is unavailable
+	err = moveKeyringItems(oldName, newName)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"prefix": "config.Config.RenameProfile",
+		}).Warnf("Could not move the keyring items of profile %s: %s", oldName, err)
+	}
+
+	if c.Profile.ProfileName == oldName {
+		c.Profile.ProfileName = newName
+	}
+
+	return viper.ReadInConfig()
+}
+
+// moveKeyringItems moves all keyring items stored for the oldName profile to
+// the newName profile, replacing any items newName already had.
+func moveKeyringItems(oldName, newName string) error {
+	// Without a keyring there are no items to move
+	if KeyRing == nil {
+		return nil
+	}
+
+	existingKeys, err := KeyRing.Keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range existingKeys {
+		if !strings.HasPrefix(key, newName+".") {
+			continue
+		}
+
+		err = KeyRing.Remove(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, key := range existingKeys {
+		if !strings.HasPrefix(key, oldName+".") {
+			continue
+		}
+
+		item, err := KeyRing.Get(key)
+		if err != nil {
+			return err
+		}
+
+		item.Key = newName + strings.TrimPrefix(key, oldName)
+		item.Label = item.Key
+
+		err = KeyRing.Set(item)
+		if err != nil {
+			return err
+		}
+
+		err = KeyRing.Remove(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func deleteLivemodeKey(key string, profile string) error {
 	fieldID := profile + "." + key
 	existingKeys, err := KeyRing.Keys()
@@ -284,10 +486,80 @@ func isProfile(value interface{}) bool {
 // WriteConfigField updates a configuration field and writes the updated
 // configuration to disk.
 func (c *Config) WriteConfigField(field string, value interface{}) error {
-	runtimeViper := viper.GetViper()
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	runtimeViper, err := readProfilesFile()
+	if err != nil {
+		return err
+	}
+
 	runtimeViper.Set(field, value)
 
-	return runtimeViper.WriteConfig()
+	err = writeConfigAtomic(runtimeViper)
+	if err != nil {
+		return err
+	}
+
+	return viper.ReadInConfig()
+}
+
+// GetDefaultProfile returns the profile selected with `stripe config use`,
+// if any
+func (c *Config) GetDefaultProfile() string {
+	return viper.GetString(DefaultProfileKey)
+}
+
+// SetDefaultProfile persists profileName as the profile commands use when no
+// project name is given. The profile must exist.
+func (c *Config) SetDefaultProfile(profileName string) error {
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	if !isProfile(v.Get(profileName)) {
+		return profileNotFoundError(v, profileName)
+	}
+
+	v.Set(DefaultProfileKey, profileName)
+
+	err = writeConfigAtomic(v)
+	if err != nil {
+		return err
+	}
+
+	c.Profile.ProfileName = profileName
+
+	return viper.ReadInConfig()
+}
+
+// readProfilesFile returns a viper instance holding only the content of the
+// profiles file. Writes must start from it rather than the global viper, which
+// also holds the bound flags and environment variables, e.g. project-name,
+// that would otherwise be persisted. A missing profiles file reads as empty.
+func readProfilesFile() (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	err := v.ReadInConfig()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return v, nil
 }
 
 // syncConfig merges a runtimeViper instance with the config file being used.
@@ -298,7 +570,7 @@ func syncConfig(runtimeViper *viper.Viper) error {
 	// Ensure we preserve the config file type
 	runtimeViper.SetConfigType(filepath.Ext(profilesFile))
 
-	err := runtimeViper.WriteConfig()
+	err := writeConfigAtomic(runtimeViper)
 	if err != nil {
 		return err
 	}
@@ -314,6 +586,11 @@ func removeKey(v *viper.Viper, key string) (*viper.Viper, error) {
 	deepestMap := deepSearch(configMap, path[0:len(path)-1])
 	delete(deepestMap, lastKey)
 
+	return newViperFromSettings(configMap)
+}
+
+// newViperFromSettings creates a new viper instance holding configMap
+func newViperFromSettings(configMap map[string]interface{}) (*viper.Viper, error) {
 	buf := new(bytes.Buffer)
 
 	encodeErr := toml.NewEncoder(buf).Encode(configMap)
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/99designs/keyring"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
@@ -18,3 +23,350 @@ func TestRemoveKey(t *testing.T) {
 	require.EqualValues(t, []string{"stay"}, nv.AllKeys())
 	require.ElementsMatch(t, []string{"stay", "remove"}, v.AllKeys())
 }
+
+func TestRenameProfile(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	c := &Config{
+		Color:    "auto",
+		LogLevel: "info",
+		Profile: Profile{
+			ProfileName: "old",
+			DeviceName:  "st-testing",
+		},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	p := Profile{
+		ProfileName:    "old",
+		DeviceName:     "st-testing",
+		TestModeAPIKey: "sk_test_123",
+		LiveModeAPIKey: "rk_live_0000000001",
+	}
+	require.NoError(t, p.CreateProfile())
+
+	err := c.RenameProfile("old", "new", false)
+	require.NoError(t, err)
+	require.Equal(t, "new", c.Profile.ProfileName)
+
+	renamed := Profile{ProfileName: "new"}
+	key, err := renamed.GetStoredAPIKey(true)
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_0000000001", key)
+
+	_, err = p.GetStoredAPIKey(true)
+	require.Error(t, err)
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.False(t, v.IsSet("old"))
+	require.Equal(t, "sk_test_123", v.GetString("new.test_mode_api_key"))
+	require.Equal(t, "st-testing", v.GetString("new.device_name"))
+}
+
+func setupDefaultProfileConfig(t *testing.T) (*Config, string) {
+	t.Helper()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := "default_profile = 'staging'\n\n[default]\ndevice_name = 'st-testing'\n\n[staging]\ndevice_name = 'st-testing'\n"
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "staging"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+	t.Cleanup(viper.Reset)
+
+	return c, profilesFile
+}
+
+func TestRenameProfileWithoutKeyring(t *testing.T) {
+	c, profilesFile := setupDefaultProfileConfig(t)
+
+	keyRing := KeyRing
+	KeyRing = nil
+	t.Cleanup(func() { KeyRing = keyRing })
+
+	require.NoError(t, c.RenameProfile("staging", "preprod", false))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.False(t, v.IsSet("staging"))
+	require.True(t, v.IsSet("preprod"))
+}
+
+func TestRenameProfileUpdatesDefaultProfile(t *testing.T) {
+	c, profilesFile := setupDefaultProfileConfig(t)
+
+	require.NoError(t, c.RenameProfile("staging", "preprod", false))
+	require.Equal(t, "preprod", c.GetDefaultProfile())
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "preprod", v.GetString(DefaultProfileKey))
+
+	// renaming another profile leaves the active one alone
+	require.NoError(t, c.RenameProfile("default", "main", false))
+	require.Equal(t, "preprod", c.GetDefaultProfile())
+}
+
+func TestRemoveProfileClearsDefaultProfile(t *testing.T) {
+	c, profilesFile := setupDefaultProfileConfig(t)
+
+	require.NoError(t, c.RemoveProfile("default"))
+	require.NoError(t, viper.ReadInConfig())
+	require.Equal(t, "staging", c.GetDefaultProfile())
+
+	require.NoError(t, c.RemoveProfile("staging"))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.False(t, v.IsSet(DefaultProfileKey))
+	require.False(t, v.IsSet("staging"))
+}
+
+func TestRemoveAllProfilesClearsDefaultProfile(t *testing.T) {
+	c, profilesFile := setupDefaultProfileConfig(t)
+
+	require.NoError(t, c.RemoveAllProfiles())
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.False(t, v.IsSet(DefaultProfileKey))
+}
+
+func TestRenameProfileExisting(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	for _, name := range []string{"old", "new"} {
+		p := Profile{ProfileName: name, DeviceName: name + "-device", TestModeAPIKey: "sk_test_123"}
+		require.NoError(t, p.CreateProfile())
+	}
+
+	replaced := Profile{ProfileName: "new", LiveModeAPIKey: "rk_live_0000000002"}
+	require.NoError(t, replaced.saveLivemodeValue(LiveModeAPIKeyName, replaced.LiveModeAPIKey, "Live mode API key"))
+
+	err := c.RenameProfile("old", "new", false)
+	require.EqualError(t, err, "profile new already exists, use --force to replace it")
+
+	key, err := replaced.GetStoredAPIKey(true)
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_0000000002", key)
+
+	err = c.RenameProfile("old", "new", true)
+	require.NoError(t, err)
+	require.Equal(t, "default", c.Profile.ProfileName)
+	require.Equal(t, "old-device", viper.GetString("new.device_name"))
+
+	// the replaced profile's keyring items must not leak into the renamed one
+	_, err = replaced.GetStoredAPIKey(true)
+	require.Error(t, err)
+}
+
+func TestRenameProfileWriteFailureKeepsKeyring(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "old"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	t.Cleanup(viper.Reset)
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	p := Profile{ProfileName: "old", DeviceName: "st-testing", LiveModeAPIKey: "rk_live_0000000001"}
+	require.NoError(t, p.CreateProfile())
+
+	renameFile = func(oldpath, newpath string) error {
+		return errors.New("disk full")
+	}
+	defer func() { renameFile = os.Rename }()
+
+	err := c.RenameProfile("old", "new", false)
+	require.Error(t, err)
+	require.Equal(t, "old", c.Profile.ProfileName)
+
+	key, err := p.GetStoredAPIKey(true)
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_0000000001", key)
+
+	renamed := Profile{ProfileName: "new"}
+	_, err = renamed.GetStoredAPIKey(true)
+	require.Error(t, err)
+}
+
+func TestInitConfigProfilesFileEnv(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	envFile := filepath.Join(t.TempDir(), "env.toml")
+	t.Setenv("STRIPE_CONFIG_FILE", envFile)
+
+	c := &Config{
+		Color:    "auto",
+		LogLevel: "info",
+		Profile:  Profile{ProfileName: "default"},
+	}
+	c.InitConfig()
+
+	require.Equal(t, envFile, c.ProfilesFile)
+	require.Equal(t, envFile, viper.ConfigFileUsed())
+}
+
+func TestInitConfigProfilesFileFlagOverridesEnv(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	flagFile := filepath.Join(t.TempDir(), "flag.toml")
+	t.Setenv("STRIPE_CONFIG_FILE", filepath.Join(t.TempDir(), "env.toml"))
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: flagFile,
+	}
+	c.InitConfig()
+
+	require.Equal(t, flagFile, c.ProfilesFile)
+	require.Equal(t, flagFile, viper.ConfigFileUsed())
+}
+
+func TestInitConfigResolvesRelativeProfilesFile(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: "profiles.toml",
+	}
+	c.InitConfig()
+
+	resolved, exists, err := c.ResolveProfilesFile()
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Equal(t, resolved, c.ProfilesFile)
+	require.Equal(t, filepath.Join(dir, "profiles.toml"), viper.ConfigFileUsed())
+}
+
+func TestResolveProfilesFileDefault(t *testing.T) {
+	xdgHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgHome)
+	t.Setenv("STRIPE_CONFIG_FILE", "")
+
+	c := &Config{}
+
+	profilesFile, exists, err := c.ResolveProfilesFile()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(xdgHome, "stripe", "config.toml"), profilesFile)
+	require.False(t, exists)
+}
+
+func TestResolveProfilesFileExplicit(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("STRIPE_CONFIG_FILE", filepath.Join(t.TempDir(), "env.toml"))
+	require.NoError(t, os.WriteFile("flag.toml", []byte("[default]\n"), 0600))
+
+	c := &Config{ProfilesFile: "flag.toml"}
+
+	profilesFile, exists, err := c.ResolveProfilesFile()
+	require.NoError(t, err)
+	require.True(t, filepath.IsAbs(profilesFile))
+	require.Equal(t, "flag.toml", filepath.Base(profilesFile))
+	require.True(t, exists)
+}
+
+func TestResolveProfilesFileEnv(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "env.toml")
+	require.NoError(t, os.WriteFile(envFile, []byte("[default]\n"), 0600))
+	t.Setenv("STRIPE_CONFIG_FILE", envFile)
+
+	c := &Config{}
+
+	profilesFile, exists, err := c.ResolveProfilesFile()
+	require.NoError(t, err)
+	require.Equal(t, envFile, profilesFile)
+	require.True(t, exists)
+}
+
+func TestSetDefaultProfile(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	p := Profile{ProfileName: "staging", DeviceName: "st-testing", TestModeAPIKey: "sk_test_123"}
+	require.NoError(t, p.CreateProfile())
+
+	err := c.SetDefaultProfile("missing")
+	require.EqualError(t, err, "profile 'missing' not found")
+	require.Equal(t, "", c.GetDefaultProfile())
+
+	require.NoError(t, c.SetDefaultProfile("staging"))
+	require.Equal(t, "staging", c.GetDefaultProfile())
+	require.Equal(t, "staging", c.Profile.ProfileName)
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "staging", v.GetString("default_profile"))
+}
+
+func TestPrintConfigSortsFields(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := "[staging]\ntest_mode_api_key = 'sk_test_123'\naccount_id = 'acct_123'\ndevice_name = 'st-testing'\ncolor = 'off'\n"
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "staging"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	printErr := c.PrintConfig()
+	os.Stdout = stdout
+
+	require.NoError(t, w.Close())
+	require.NoError(t, printErr)
+
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "[staging]\n  account_id=acct_123\n  color=off\n  device_name=st-testing\n  test_mode_api_key=sk_test_123\n", string(out))
+}
@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+// statuses returned by ExpiryStatus
+const (
+	ExpiryValid        = "valid"
+	ExpiryExpiringSoon = "expiring_soon"
+	ExpiryExpired      = "expired"
+	ExpiryUnknown      = "unknown"
+)
+
+// ExpiryStatus classifies a key expiring at expiresAt as of now. It is
+// expiring soon when it expires within warnWithin, and unknown when expiresAt
+// is the zero time. remaining is negative once the key has expired.
+func ExpiryStatus(expiresAt time.Time, now time.Time, warnWithin time.Duration) (status string, remaining time.Duration) {
+	if expiresAt.IsZero() {
+		return ExpiryUnknown, 0
+	}
+
+	remaining = expiresAt.Sub(now)
+
+	switch {
+	case remaining < 0:
+		return ExpiryExpired, remaining
+	case remaining < warnWithin:
+		return ExpiryExpiringSoon, remaining
+	default:
+		return ExpiryValid, remaining
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiryStatus(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	warnWithin := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		status    string
+		remaining time.Duration
+	}{
+		{name: "valid", expiresAt: now.AddDate(0, 0, 30), status: ExpiryValid, remaining: 30 * 24 * time.Hour},
+		{name: "expiring soon", expiresAt: now.AddDate(0, 0, 3), status: ExpiryExpiringSoon, remaining: 3 * 24 * time.Hour},
+		{name: "expired", expiresAt: now.Add(-time.Hour), status: ExpiryExpired, remaining: -time.Hour},
+		{name: "unknown", expiresAt: time.Time{}, status: ExpiryUnknown, remaining: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, remaining := ExpiryStatus(tt.expiresAt, now, warnWithin)
+			require.Equal(t, tt.status, status)
+			require.Equal(t, tt.remaining, remaining)
+		})
+	}
+}
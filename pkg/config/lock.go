@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"sync"
+)
+
+// profilesFileMutex serializes writes to the profiles file within this process
+var profilesFileMutex sync.Mutex
+
+// lockProfilesFile takes an exclusive lock on the profiles file so that
+// read-modify-write operations on it, including ones from other CLI processes,
+// don't interleave. The returned function releases the lock.
+func lockProfilesFile(profilesFile string) (func(), error) {
+	profilesFileMutex.Lock()
+
+	err := makePath(profilesFile)
+	if err != nil {
+		profilesFileMutex.Unlock()
+		return nil, err
+	}
+
+	// Lock a separate file, since the profiles file itself may be replaced
+	// while the lock is held
+	lock, err := os.OpenFile(profilesFile+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		profilesFileMutex.Unlock()
+		return nil, err
+	}
+
+	err = lockFile(lock)
+	if err != nil {
+		lock.Close()
+		profilesFileMutex.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		unlockFile(lock)
+		lock.Close()
+		profilesFileMutex.Unlock()
+	}, nil
+}
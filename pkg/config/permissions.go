@@ -0,0 +1,33 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// FilePermissionsSupported is whether files have unix permissions, which
+// Windows doesn't support
+var FilePermissionsSupported = runtime.GOOS != "windows"
+
+// ErrInsecurePermissions is returned by CheckFilePermissions for files that
+// can be read by others than their owner
+var ErrInsecurePermissions = errors.New("insecure permissions")
+
+// CheckFilePermissions checks that the file at path, which holds secrets, can
+// only be accessed by its owner, and returns its permissions. Permissions are
+// not checked when FilePermissionsSupported is false.
+func CheckFilePermissions(path string) (os.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	perm := info.Mode().Perm()
+	if FilePermissionsSupported && perm&0077 != 0 {
+		return perm, fmt.Errorf("%s has %w %04o, run `chmod 600 %s`", path, ErrInsecurePermissions, perm, path)
+	}
+
+	return perm, nil
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions are not supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[default]\n"), 0600))
+
+	perm, err := CheckFilePermissions(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), perm)
+
+	require.NoError(t, os.Chmod(path, 0644))
+
+	perm, err = CheckFilePermissions(path)
+	require.ErrorIs(t, err, ErrInsecurePermissions)
+	require.EqualError(t, err, path+" has insecure permissions 0644, run `chmod 600 "+path+"`")
+	require.Equal(t, os.FileMode(0644), perm)
+
+	_, err = CheckFilePermissions(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
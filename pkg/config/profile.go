@@ -1,15 +1,20 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/99designs/keyring"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
 	"github.com/stripe/stripe-cli/pkg/ansi"
@@ -28,6 +33,10 @@ type Profile struct {
 	TerminalPOSDeviceID    string
 	DisplayName            string
 	AccountID              string
+
+	// UseKeyring stores the test mode API key in the keyring, like the live
+	// mode API key, and only its redacted form in the profiles file
+	UseKeyring bool
 }
 
 // config key names
@@ -42,15 +51,27 @@ const (
 	LiveModeAPIKeyName         = "live_mode_api_key"
 	LiveModePubKeyName         = "live_mode_pub_key"
 	LiveModeKeyExpiresAtName   = "live_mode_key_expires_at"
+	KeyExpiryWarnDaysName      = "key_expiry_warn_days"
+	APIVersionName             = "api_version"
+	StripeAccountName          = "stripe_account"
 )
 
 const (
+	// DefaultProfileName is the name of the profile used when none is
+	// specified. Its section also provides defaults for the settings other
+	// profiles don't set.
+	DefaultProfileName = "default"
+
 	// DateStringFormat is the format for expiredAt date
 	DateStringFormat = "2006-01-02"
 
 	// KeyValidInDays is the number of days the API key is valid for
 	KeyValidInDays = 90
 
+	// DefaultKeyExpiryWarnDays is the number of days before a key expires
+	// that it is considered to be expiring soon, unless configured otherwise
+	DefaultKeyExpiryWarnDays = 7
+
 	// KeyManagementService is the key management service name
 	KeyManagementService = "StripeCLI"
 )
@@ -58,13 +79,48 @@ const (
 // KeyRing ...
 var KeyRing keyring.Keyring
 
-// CreateProfile creates a profile when logging in
+// ErrKeyringUnavailable is returned when the keyring holding livemode values
+// can't be accessed, as opposed to the value not being stored in it
+var ErrKeyringUnavailable = errors.New("the keyring is unavailable")
+
+// CreateProfile creates a profile when logging in. Fields already stored for
+// the profile (e.g. color or account_id) are preserved, and only the non-empty
+// fields of p are written over them.
 func (p *Profile) CreateProfile() error {
-	// Remove all keys under existing profile first
-	v := p.deleteProfile(viper.GetViper())
+	return p.mergeProfile(true)
+}
+
+// UpdateProfile writes the non-empty fields of p over the profile like
+// CreateProfile, but keeps the API keys already stored for both modes. It's
+// used when logging in with a single key, which only replaces its own slot.
+func (p *Profile) UpdateProfile() error {
+	return p.mergeProfile(false)
+}
+
+func (p *Profile) mergeProfile(dropStaleLiveKey bool) error {
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
 
-	// Fail open to avoid blocking login
-	p.deleteLivemodeValue(LiveModeAPIKeyName)
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	// A missing profiles file is fine, it gets created when writing the profile
+	v.ReadInConfig()
+
+	// A previously stored live mode key is stale once we log in again, so drop
+	// it from the config file unless it's being replaced
+	if dropStaleLiveKey {
+		if p.LiveModeAPIKey == "" {
+			v = p.safeRemove(v, LiveModeAPIKeyName)
+			v = p.safeRemove(v, LiveModeKeyExpiresAtName)
+		}
+
+		// Fail open to avoid blocking login
+		p.deleteLivemodeValue(LiveModeAPIKeyName)
+	}
 
 	writeErr := p.writeProfile(v)
 	if writeErr != nil {
@@ -74,19 +130,6 @@ func (p *Profile) CreateProfile() error {
 	return nil
 }
 
-func (p *Profile) deleteProfile(v *viper.Viper) *viper.Viper {
-	for _, key := range v.AllKeys() {
-		if strings.HasPrefix(key, p.ProfileName+".") {
-			newViper, err := removeKey(v, key)
-			if err == nil {
-				// failure to remove a key should not break the login flow
-				v = newViper
-			}
-		}
-	}
-	return v
-}
-
 // GetColor gets the color setting for the user based on the flag or the
 // persisted color stored in the config file
 func (p *Profile) GetColor() (string, error) {
@@ -95,7 +138,7 @@ func (p *Profile) GetColor() (string, error) {
 		return color, nil
 	}
 
-	color = viper.GetString(p.GetConfigField("color"))
+	color = p.getInheritedString("color")
 	switch color {
 	case "", ColorAuto:
 		return ColorAuto, nil
@@ -119,7 +162,7 @@ func (p *Profile) GetDeviceName() (string, error) {
 	}
 
 	if err := viper.ReadInConfig(); err == nil {
-		return viper.GetString(p.GetConfigField(DeviceNameName)), nil
+		return p.getInheritedString(DeviceNameName), nil
 	}
 
 	return "", validators.ErrDeviceNameNotConfigured
@@ -175,6 +218,14 @@ func (p *Profile) GetAPIKey(livemode bool) (string, error) {
 		if err := viper.ReadInConfig(); err == nil {
 			key = viper.GetString(p.GetConfigField(TestModeAPIKeyName))
 		}
+
+		// The key was stored in the keyring with login --keyring
+		if isRedactedAPIKey(key) {
+			key, err = p.retrieveLivemodeValue(TestModeAPIKeyName)
+			if err != nil {
+				return "", err
+			}
+		}
 	} else {
 		p.redactAllLivemodeValues()
 		key, err = p.retrieveLivemodeValue(LiveModeAPIKeyName)
@@ -188,33 +239,222 @@ func (p *Profile) GetAPIKey(livemode bool) (string, error) {
 		if err != nil {
 			return "", err
 		}
+
+		warnKeySlotMismatch(key, livemode)
+
 		return key, nil
 	}
 
 	return "", validators.ErrAPIKeyNotConfigured
 }
 
+// Sources a configuration value can be resolved from
+const (
+	SourceEnv    = "env"
+	SourceFlag   = "flag"
+	SourceConfig = "config"
+)
+
+// ValueSource describes where a configuration value was resolved from
+type ValueSource struct {
+	// Source is one of SourceEnv, SourceFlag or SourceConfig
+	Source string
+
+	// ShadowsConfig is whether a value stored in the profiles file was
+	// ignored in favor of the environment or a flag
+	ShadowsConfig bool
+}
+
+// warnKeySlotMismatch warns when a key stored for one mode belongs to the
+// other, e.g. a live mode key stored as the test mode key
+func warnKeySlotMismatch(key string, livemode bool) {
+	mode, _, err := validators.ParseAPIKey(key)
+	if err != nil {
+		return
+	}
+
+	if !livemode && mode == "live" {
+		log.WithFields(log.Fields{
+			"prefix": "config.Profile.GetAPIKey",
+		}).Warnf("%s appears to be a LIVE key! Commands will run against live data.", TestModeAPIKeyName)
+	} else if livemode && mode == "test" {
+		log.WithFields(log.Fields{
+			"prefix": "config.Profile.GetAPIKey",
+		}).Warnf("%s appears to be a TEST key!", LiveModeAPIKeyName)
+	}
+}
+
+// GetAPIKeyWithSource returns the same key as GetAPIKey, along with where it
+// was resolved from
+func (p *Profile) GetAPIKeyWithSource(livemode bool) (string, ValueSource, error) {
+	source := ValueSource{Source: SourceConfig}
+
+	if os.Getenv("STRIPE_API_KEY") != "" {
+		source.Source = SourceEnv
+	} else if p.APIKey != "" {
+		source.Source = SourceFlag
+	}
+
+	key, err := p.GetAPIKey(livemode)
+	if err != nil {
+		return "", source, err
+	}
+
+	if source.Source != SourceConfig {
+		storedKey, _ := p.GetStoredAPIKey(livemode)
+		source.ShadowsConfig = storedKey != "" && storedKey != key
+	}
+
+	return key, source, nil
+}
+
+// GetStoredAPIKey returns the key stored for the given profile, ignoring the
+// STRIPE_API_KEY environment variable and the --api-key flag
+func (p *Profile) GetStoredAPIKey(livemode bool) (string, error) {
+	if livemode {
+		return p.retrieveLivemodeValue(LiveModeAPIKeyName)
+	}
+
+	if err := viper.ReadInConfig(); err == nil {
+		key := viper.GetString(p.GetConfigField(TestModeAPIKeyName))
+		if isRedactedAPIKey(key) {
+			return p.retrieveLivemodeValue(TestModeAPIKeyName)
+		}
+
+		if key != "" {
+			return key, nil
+		}
+	}
+
+	return "", validators.ErrAPIKeyNotConfigured
+}
+
+// IsAPIKeyInKeyring returns whether the API key of the given mode is stored
+// in the keyring. Live mode keys always are.
+func (p *Profile) IsAPIKeyInKeyring(livemode bool) bool {
+	return livemode || isRedactedAPIKey(viper.GetString(p.GetConfigField(TestModeAPIKeyName)))
+}
+
 // GetExpiresAt returns the API key expirary date
 func (p *Profile) GetExpiresAt(livemode bool) (time.Time, error) {
-	var timeString string
-
+	field := TestModeKeyExpiresAtName
 	if livemode {
-		timeString = viper.GetString(p.GetConfigField(LiveModeKeyExpiresAtName))
-	} else {
-		timeString = viper.GetString(p.GetConfigField(TestModeKeyExpiresAtName))
+		field = LiveModeKeyExpiresAtName
 	}
 
+	timeString := viper.GetString(p.GetConfigField(field))
+
 	if timeString != "" {
-		expiresAt, err := time.Parse(DateStringFormat, timeString)
-		if err != nil {
-			return time.Time{}, err
-		}
-		return expiresAt, nil
+		return ParseExpiresAt(field, timeString)
 	}
 
 	return time.Time{}, validators.ErrAPIKeyNotConfigured
 }
 
+// expiresAtFallbackFormats are the other date formats accepted for expiry
+// dates, which configs written by other tools may use
+var expiresAtFallbackFormats = []string{time.RFC3339, "2006/01/02", "01/02/2006"}
+
+// ParseExpiresAt parses an expiry date in DateStringFormat, or else in one of
+// the expiresAtFallbackFormats
+func ParseExpiresAt(field, value string) (time.Time, error) {
+	for _, layout := range append([]string{DateStringFormat}, expiresAtFallbackFormats...) {
+		expiresAt, err := time.Parse(layout, value)
+		if err == nil {
+			return expiresAt, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%s value not supported: %s", field, value)
+}
+
+// GetKeyExpiryWarnDays returns the number of days before a key expires that
+// it is considered to be expiring soon
+func (p *Profile) GetKeyExpiryWarnDays() (int, error) {
+	if err := viper.ReadInConfig(); err == nil && p.getInheritedString(KeyExpiryWarnDaysName) != "" {
+		value := p.getInheritedString(KeyExpiryWarnDaysName)
+
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("%s value not supported: %s", KeyExpiryWarnDaysName, value)
+		}
+
+		return days, nil
+	}
+
+	return DefaultKeyExpiryWarnDays, nil
+}
+
+// SetKeyExpiryWarnDays persists the number of days before a key expires that
+// it is considered to be expiring soon
+func (p *Profile) SetKeyExpiryWarnDays(days int) error {
+	if days < 0 {
+		return fmt.Errorf("%s must not be negative", KeyExpiryWarnDaysName)
+	}
+
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	v, err := readProfilesFile()
+	if err != nil {
+		return err
+	}
+
+	v.Set(p.GetConfigField(KeyExpiryWarnDaysName), days)
+
+	err = writeConfigAtomic(v)
+	if err != nil {
+		return err
+	}
+
+	return viper.ReadInConfig()
+}
+
+// apiVersionPattern matches the YYYY-MM-DD form of Stripe API versions
+var apiVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// GetAPIVersion returns the Stripe API version the profile is pinned to, or
+// an empty string to use the account's default version
+func (p *Profile) GetAPIVersion() string {
+	if err := viper.ReadInConfig(); err == nil {
+		return viper.GetString(p.GetConfigField(APIVersionName))
+	}
+
+	return ""
+}
+
+// SetAPIVersion pins the profile to a Stripe API version
+func (p *Profile) SetAPIVersion(version string) error {
+	if !apiVersionPattern.MatchString(version) {
+		return fmt.Errorf("%s must be formatted as YYYY-MM-DD: %s", APIVersionName, version)
+	}
+
+	return p.WriteConfigField(APIVersionName, version)
+}
+
+// GetStripeAccount returns the connected account stored in the profile, which
+// verifying the API key checks access to, or an empty string if there is none
+func (p *Profile) GetStripeAccount() string {
+	if err := viper.ReadInConfig(); err == nil {
+		return viper.GetString(p.GetConfigField(StripeAccountName))
+	}
+
+	return ""
+}
+
+// SetStripeAccount stores the connected account verifying the API key checks
+// access to
+func (p *Profile) SetStripeAccount(accountID string) error {
+	if err := validators.AccountID(accountID); err != nil {
+		return err
+	}
+
+	return p.WriteConfigField(StripeAccountName, accountID)
+}
+
 // GetPublishableKey returns the publishable key for the user
 func (p *Profile) GetPublishableKey(livemode bool) (string, error) {
 	var fieldID string
@@ -271,6 +511,18 @@ func (p *Profile) GetConfigField(field string) string {
 	return p.ProfileName + "." + field
 }
 
+// getInheritedString returns the value of a setting for the profile, falling
+// back to the default profile's value when the profile doesn't set it. Only
+// settings shared across projects should be read this way, never keys or
+// account identifiers.
+func (p *Profile) getInheritedString(field string) string {
+	if viper.IsSet(p.GetConfigField(field)) {
+		return viper.GetString(p.GetConfigField(field))
+	}
+
+	return viper.GetString(DefaultProfileName + "." + field)
+}
+
 // RegisterAlias registers an alias for a given key.
 func (p *Profile) RegisterAlias(alias, key string) {
 	viper.RegisterAlias(p.GetConfigField(alias), p.GetConfigField(key))
@@ -279,14 +531,41 @@ func (p *Profile) RegisterAlias(alias, key string) {
 // WriteConfigField updates a configuration field and writes the updated
 // configuration to disk.
 func (p *Profile) WriteConfigField(field, value string) error {
-	viper.ReadInConfig()
-	viper.Set(p.GetConfigField(field), value)
-	return viper.WriteConfig()
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	v, err := readProfilesFile()
+	if err != nil {
+		return err
+	}
+
+	v.Set(p.GetConfigField(field), value)
+
+	err = writeConfigAtomic(v)
+	if err != nil {
+		return err
+	}
+
+	return viper.ReadInConfig()
 }
 
 // DeleteConfigField deletes a configuration field.
 func (p *Profile) DeleteConfigField(field string) error {
-	v, err := removeKey(viper.GetViper(), p.GetConfigField(field))
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	v, err := readProfilesFile()
+	if err != nil {
+		return err
+	}
+
+	v, err = removeKey(v, p.GetConfigField(field))
 	if err != nil {
 		return err
 	}
@@ -299,6 +578,211 @@ func (p *Profile) DeleteConfigField(field string) error {
 	return p.writeProfile(v)
 }
 
+// StorageKind is where an API key is stored
+type StorageKind int
+
+const (
+	// StorageFile stores the API key in the profiles file
+	StorageFile StorageKind = iota
+
+	// StorageKeyring stores the API key in the keyring, and only its redacted
+	// form in the profiles file
+	StorageKeyring
+)
+
+// SetAPIKey stores the API key of the given mode in storage and removes it
+// from the other location. Live mode keys can only be stored in the keyring.
+func (p *Profile) SetAPIKey(livemode bool, key string, storage StorageKind) error {
+	if livemode && storage != StorageKeyring {
+		return errors.New("live mode keys can only be stored in the keyring")
+	}
+
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	// A missing profiles file is fine, it gets created when writing the key
+	v.ReadInConfig()
+
+	keyProfile := Profile{
+		ProfileName: p.ProfileName,
+		UseKeyring:  storage == StorageKeyring,
+	}
+
+	if livemode {
+		keyProfile.LiveModeAPIKey = key
+	} else {
+		keyProfile.TestModeAPIKey = key
+	}
+
+	err := keyProfile.writeProfile(v)
+	if err != nil {
+		return err
+	}
+
+	if livemode {
+		p.LiveModeAPIKey = key
+	} else {
+		p.TestModeAPIKey = key
+		p.UseKeyring = keyProfile.UseKeyring
+	}
+
+	return viper.ReadInConfig()
+}
+
+// ReplaceLiveModeAPIKey rotates the live mode API key stored in the keyring
+// for the profile. The new key is stored before the profiles file is updated,
+// and the old key is restored if the file can't be written, so that a failed
+// rotation never leaves the profile without a live mode key.
+func (p *Profile) ReplaceLiveModeAPIKey(apiKey string) error {
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	oldKey, err := p.retrieveLivemodeValue(LiveModeAPIKeyName)
+	if err != nil {
+		return err
+	}
+
+	apiKey = strings.TrimSpace(apiKey)
+
+	err = p.saveLivemodeValue(LiveModeAPIKeyName, apiKey, "Live mode API key")
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrKeyringUnavailable, err)
+	}
+
+	profilesFile := viper.ConfigFileUsed()
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+
+	err = v.ReadInConfig()
+	if err == nil {
+		v.Set(p.GetConfigField(LiveModeAPIKeyName), RedactAPIKey(apiKey))
+		v.Set(p.GetConfigField(LiveModeKeyExpiresAtName), getKeyExpiresAt())
+
+		err = writeConfigAtomic(v)
+	}
+
+	if err != nil {
+		p.saveLivemodeValue(LiveModeAPIKeyName, oldKey, "Live mode API key") // #nosec G104
+		return err
+	}
+
+	return viper.ReadInConfig()
+}
+
+// Clear removes the API keys of the profile, with their publishable keys and
+// expiry dates, from both the profiles file and the keyring. A nil livemode
+// clears both modes. The keyring items are restored if the profiles file
+// can't be written, so that the profile is never left half cleared.
+func (p *Profile) Clear(livemode *bool) error {
+	modes := []bool{false, true}
+	if livemode != nil {
+		modes = []bool{*livemode}
+	}
+
+	var fields, keyringFields []string
+
+	for _, live := range modes {
+		if live {
+			fields = append(fields, LiveModeAPIKeyName, LiveModePubKeyName, LiveModeKeyExpiresAtName)
+			keyringFields = append(keyringFields, LiveModeAPIKeyName)
+		} else {
+			fields = append(fields, TestModeAPIKeyName, TestModePubKeyName, TestModeKeyExpiresAtName)
+			keyringFields = append(keyringFields, TestModeAPIKeyName)
+		}
+	}
+
+	unlock, lockErr := lockProfilesFile(viper.ConfigFileUsed())
+	if lockErr != nil {
+		return lockErr
+	}
+	defer unlock()
+
+	profilesFile := viper.ConfigFileUsed()
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if v.IsSet(p.GetConfigField(field)) {
+			v, err = removeKey(v, p.GetConfigField(field))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	removed, err := p.removeKeyringItems(keyringFields)
+	if err != nil {
+		return err
+	}
+
+	v.SetConfigFile(profilesFile)
+	v.SetConfigType(filepath.Ext(profilesFile))
+
+	err = writeConfigAtomic(v)
+	if err != nil {
+		restoreKeyringItems(removed)
+		return err
+	}
+
+	return viper.ReadInConfig()
+}
+
+// removeKeyringItems removes the keyring items of fields and returns them, so
+// that they can be restored. Nothing can be stored for the profile when the
+// keyring is unavailable.
+func (p *Profile) removeKeyringItems(fields []string) ([]keyring.Item, error) {
+	if KeyRing == nil {
+		return nil, nil
+	}
+
+	var removed []keyring.Item
+
+	for _, field := range fields {
+		fieldID := p.GetConfigField(field)
+
+		item, err := KeyRing.Get(fieldID)
+		if err == keyring.ErrKeyNotFound {
+			continue
+		}
+
+		if err == nil {
+			err = KeyRing.Remove(fieldID)
+		}
+
+		if err != nil {
+			restoreKeyringItems(removed)
+			return nil, fmt.Errorf("%w: %s", ErrKeyringUnavailable, err)
+		}
+
+		removed = append(removed, item)
+	}
+
+	return removed, nil
+}
+
+// restoreKeyringItems stores back the items removed by removeKeyringItems
+func restoreKeyringItems(items []keyring.Item) {
+	for _, item := range items {
+		KeyRing.Set(item) // #nosec G104
+	}
+}
+
 func (p *Profile) writeProfile(runtimeViper *viper.Viper) error {
 	profilesFile := viper.ConfigFileUsed()
 
@@ -327,7 +811,23 @@ func (p *Profile) writeProfile(runtimeViper *viper.Viper) error {
 	}
 
 	if p.TestModeAPIKey != "" {
-		runtimeViper.Set(p.GetConfigField(TestModeAPIKeyName), strings.TrimSpace(p.TestModeAPIKey))
+		testModeAPIKey := strings.TrimSpace(p.TestModeAPIKey)
+
+		if p.UseKeyring {
+			// The key would be lost if it can't be stored, so don't fail open
+			err = p.saveLivemodeValue(TestModeAPIKeyName, testModeAPIKey, "Test mode API key")
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrKeyringUnavailable, err)
+			}
+
+			runtimeViper.Set(p.GetConfigField(TestModeAPIKeyName), RedactAPIKey(testModeAPIKey))
+		} else {
+			runtimeViper.Set(p.GetConfigField(TestModeAPIKeyName), testModeAPIKey)
+
+			// Fail open, a stale keyring item is ignored once the key is in the config
+			p.deleteLivemodeValue(TestModeAPIKeyName)
+		}
+
 		runtimeViper.Set(p.GetConfigField(TestModeKeyExpiresAtName), getKeyExpiresAt())
 	}
 
@@ -360,7 +860,7 @@ func (p *Profile) writeProfile(runtimeViper *viper.Viper) error {
 	// Ensure we preserve the config file type
 	runtimeViper.SetConfigType(filepath.Ext(profilesFile))
 
-	err = runtimeViper.WriteConfig()
+	err = writeConfigAtomic(runtimeViper)
 	if err != nil {
 		return err
 	}
@@ -419,8 +919,22 @@ func RedactAPIKey(apiKey string) string {
 	return b.String()
 }
 
+// APIKeyFingerprint returns a short, stable fingerprint of an API key: the
+// first 8 hex characters of its SHA-256 hash. Unlike the key itself, the
+// fingerprint is safe to share and compare across machines.
+func APIKeyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+
+	return hex.EncodeToString(sum[:])[0:8]
+}
+
 // isRedactedAPIKey checks if the input string is a refacted api key
 func isRedactedAPIKey(apiKey string) bool {
+	// RedactAPIKey only supports keys long enough to be redacted
+	if len(apiKey) < 12 {
+		return false
+	}
+
 	keyParts := strings.Split(apiKey, "_")
 	if len(keyParts) < 3 {
 		return false
@@ -442,9 +956,13 @@ func getKeyExpiresAt() string {
 }
 
 // saveLivemodeValue saves livemode value of given key in keyring
-func (p *Profile) saveLivemodeValue(field, value, description string) {
+func (p *Profile) saveLivemodeValue(field, value, description string) error {
+	if KeyRing == nil {
+		return ErrKeyringUnavailable
+	}
+
 	fieldID := p.GetConfigField(field)
-	_ = KeyRing.Set(keyring.Item{
+	return KeyRing.Set(keyring.Item{
 		Key:         fieldID,
 		Data:        []byte(value),
 		Description: description,
@@ -454,15 +972,23 @@ func (p *Profile) saveLivemodeValue(field, value, description string) {
 
 // retrieveLivemodeValue retrieves livemode value of given key in keyring
 func (p *Profile) retrieveLivemodeValue(key string) (string, error) {
+	if KeyRing == nil {
+		return "", ErrKeyringUnavailable
+	}
+
 	fieldID := p.GetConfigField(key)
 	existingKeys, err := KeyRing.Keys()
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %s", ErrKeyringUnavailable, err)
 	}
 
 	for _, item := range existingKeys {
 		if item == fieldID {
-			value, _ := KeyRing.Get(fieldID)
+			value, err := KeyRing.Get(fieldID)
+			if err != nil {
+				return "", fmt.Errorf("%w: %s", ErrKeyringUnavailable, err)
+			}
+
 			return string(value.Data), nil
 		}
 	}
@@ -472,6 +998,10 @@ func (p *Profile) retrieveLivemodeValue(key string) (string, error) {
 
 // deleteLivemodeValue deletes livemode value of given key in keyring
 func (p *Profile) deleteLivemodeValue(key string) error {
+	if KeyRing == nil {
+		return ErrKeyringUnavailable
+	}
+
 	fieldID := p.GetConfigField(key)
 	existingKeys, err := KeyRing.Keys()
 	if err != nil {
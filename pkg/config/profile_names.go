@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// maxSuggestionDistance is the largest edit distance between a missing
+// profile name and an existing one for the latter to be suggested
+const maxSuggestionDistance = 2
+
+// ProfileExists returns whether the profiles file has a profile named name
+func (c *Config) ProfileExists(name string) bool {
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	if err := v.ReadInConfig(); err != nil {
+		return false
+	}
+
+	return isProfile(v.Get(name))
+}
+
+// SuggestProfileName returns the existing profile whose name is the closest
+// to name, or an empty string when none is close enough to be a typo
+func (c *Config) SuggestProfileName(name string) string {
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+
+	if err := v.ReadInConfig(); err != nil {
+		return ""
+	}
+
+	return suggestProfileName(profileNames(v), name)
+}
+
+// profileNotFoundError describes why the profile name can't be found in v,
+// suggesting the closest profile name or to log in when there are none
+func profileNotFoundError(v *viper.Viper, name string) error {
+	names := profileNames(v)
+	if len(names) == 0 {
+		return fmt.Errorf("profile '%s' not found, there are no profiles yet: run `stripe login` to create one", name)
+	}
+
+	if suggestion := suggestProfileName(names, name); suggestion != "" {
+		return fmt.Errorf("profile '%s' not found (did you mean '%s'?)", name, suggestion)
+	}
+
+	return fmt.Errorf("profile '%s' not found", name)
+}
+
+// profileNames returns the sorted names of the profiles in v
+func profileNames(v *viper.Viper) []string {
+	names := []string{}
+
+	for name, value := range v.AllSettings() {
+		if isProfile(value) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func suggestProfileName(names []string, name string) string {
+	suggestion := ""
+	bestDistance := maxSuggestionDistance + 1
+
+	for _, candidate := range names {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance < bestDistance {
+			suggestion = candidate
+			bestDistance = distance
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(b)]
+}
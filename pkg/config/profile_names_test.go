@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func setupProfileNamesConfig(t *testing.T, content string) *Config {
+	t.Helper()
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	t.Cleanup(viper.Reset)
+
+	return c
+}
+
+func TestProfileExists(t *testing.T) {
+	c := setupProfileNamesConfig(t, "color = 'on'\n\n[staging]\ndevice_name = 'st-testing'\n")
+
+	require.True(t, c.ProfileExists("staging"))
+	require.False(t, c.ProfileExists("stagng"))
+	require.False(t, c.ProfileExists("color"))
+}
+
+func TestProfileNotFoundSuggestion(t *testing.T) {
+	c := setupProfileNamesConfig(t, "[production]\ndevice_name = 'st-testing'\n\n[staging]\ndevice_name = 'st-testing'\n")
+
+	require.Equal(t, "staging", c.SuggestProfileName("stagng"))
+	require.Equal(t, "staging", c.SuggestProfileName("Staging"))
+	require.Equal(t, "", c.SuggestProfileName("sandbox"))
+
+	err := c.SetDefaultProfile("stagng")
+	require.EqualError(t, err, "profile 'stagng' not found (did you mean 'staging'?)")
+
+	err = c.SetDefaultProfile("sandbox")
+	require.EqualError(t, err, "profile 'sandbox' not found")
+}
+
+func TestProfileNotFoundEmptyConfig(t *testing.T) {
+	c := setupProfileNamesConfig(t, "color = 'on'\n")
+
+	require.False(t, c.ProfileExists("default"))
+	require.Equal(t, "", c.SuggestProfileName("default"))
+
+	err := c.SetDefaultProfile("default")
+	require.EqualError(t, err, "profile 'default' not found, there are no profiles yet: run `stripe login` to create one")
+}
+
+func TestEditDistance(t *testing.T) {
+	require.Equal(t, 0, editDistance("staging", "staging"))
+	require.Equal(t, 1, editDistance("stagng", "staging"))
+	require.Equal(t, 2, editDistance("stagign", "staging"))
+	require.Equal(t, 3, editDistance("", "abc"))
+}
@@ -1,14 +1,23 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/99designs/keyring"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/validators"
 )
 
 func TestWriteProfile(t *testing.T) {
@@ -39,7 +48,9 @@ func TestWriteProfile(t *testing.T) {
 
 	configValues := helperLoadBytes(t, c.ProfilesFile)
 	expiresAt := getKeyExpiresAt()
-	expectedConfig := `[tests]
+	expectedConfig := `schema_version = 1
+
+[tests]
 device_name = 'st-testing'
 display_name = 'test-account-display-name'
 test_mode_api_key = 'sk_test_123'
@@ -81,7 +92,9 @@ func TestWriteProfilesMerge(t *testing.T) {
 
 	configValues := helperLoadBytes(t, c.ProfilesFile)
 	expiresAt := getKeyExpiresAt()
-	expectedConfig := `[tests]
+	expectedConfig := `schema_version = 1
+
+[tests]
 device_name = 'st-testing'
 display_name = 'test-account-display-name'
 test_mode_api_key = 'sk_test_123'
@@ -99,7 +112,7 @@ test_mode_key_expires_at = '` + expiresAt + `'
 	cleanUp(c.ProfilesFile)
 }
 
-func TestOldProfileDeleted(t *testing.T) {
+func TestOldProfileFieldsOverwritten(t *testing.T) {
 	profilesFile := filepath.Join(os.TempDir(), "stripe", "config.toml")
 	p := Profile{
 		ProfileName:    "test",
@@ -138,7 +151,6 @@ func TestOldProfileDeleted(t *testing.T) {
 		DisplayName:    "",
 	}
 
-	v = p.deleteProfile(v)
 	err = p.writeProfile(v)
 	require.NoError(t, err)
 
@@ -147,10 +159,8 @@ func TestOldProfileDeleted(t *testing.T) {
 	// Overwrites keys
 	require.Equal(t, "device-after-test", v.GetString(p.GetConfigField(DeviceNameName)))
 	require.Equal(t, "sk_test_456", v.GetString(p.GetConfigField(TestModeAPIKeyName)))
-	require.Equal(t, "", v.GetString(p.GetConfigField(DisplayNameName)))
-	// Deletes nested keys
-	require.False(t, v.IsSet(v.GetString(p.GetConfigField("experimental.stripe_headers"))))
-	require.False(t, v.IsSet(v.GetString(p.GetConfigField("experimental"))))
+	// Keeps the fields that aren't written
+	require.Equal(t, "display-name-before-test", v.GetString(p.GetConfigField(DisplayNameName)))
 	// Leaves the other profile untouched
 	require.Equal(t, "foo-device-name", v.GetString(untouchedProfile.GetConfigField(DeviceNameName)))
 	require.Equal(t, "foo_test_123", v.GetString(untouchedProfile.GetConfigField(TestModeAPIKeyName)))
@@ -280,6 +290,43 @@ func TestLiveModeAPIKeyKeychainItemReplaced(t *testing.T) {
 	cleanUp(c.ProfilesFile)
 }
 
+func TestCreateProfilePreservesExistingFields(t *testing.T) {
+	profilesFile := filepath.Join(os.TempDir(), "stripe", "config.toml")
+	p := Profile{
+		ProfileName: "merge",
+		DeviceName:  "st-testing",
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	err := p.WriteConfigField("color", "off")
+	require.NoError(t, err)
+	err = p.WriteConfigField(AccountIDName, "acct_123")
+	require.NoError(t, err)
+
+	p.TestModeAPIKey = "sk_test_456"
+	err = p.CreateProfile()
+	require.NoError(t, err)
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	err = v.ReadInConfig()
+	require.NoError(t, err)
+
+	require.Equal(t, "off", v.GetString(p.GetConfigField("color")))
+	require.Equal(t, "acct_123", v.GetString(p.GetConfigField(AccountIDName)))
+	require.Equal(t, "sk_test_456", v.GetString(p.GetConfigField(TestModeAPIKeyName)))
+	require.Equal(t, "st-testing", v.GetString(p.GetConfigField(DeviceNameName)))
+
+	cleanUp(c.ProfilesFile)
+}
+
 func helperLoadBytes(t *testing.T, name string) []byte {
 	bytes, err := os.ReadFile(name)
 	if err != nil {
@@ -292,3 +339,625 @@ func helperLoadBytes(t *testing.T, name string) []byte {
 func cleanUp(file string) {
 	os.Remove(file)
 }
+
+func TestKeyExpiryWarnDays(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	p := Profile{
+		ProfileName: "expiry",
+		DeviceName:  "st-testing",
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+	require.NoError(t, p.CreateProfile())
+
+	days, err := p.GetKeyExpiryWarnDays()
+	require.NoError(t, err)
+	require.Equal(t, DefaultKeyExpiryWarnDays, days)
+
+	err = p.SetKeyExpiryWarnDays(14)
+	require.NoError(t, err)
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, 14, v.GetInt("expiry.key_expiry_warn_days"))
+
+	days, err = p.GetKeyExpiryWarnDays()
+	require.NoError(t, err)
+	require.Equal(t, 14, days)
+
+	require.Error(t, p.SetKeyExpiryWarnDays(-1))
+
+	// values set with `stripe config --set` are stored as strings
+	require.NoError(t, p.WriteConfigField(KeyExpiryWarnDaysName, "30"))
+	days, err = p.GetKeyExpiryWarnDays()
+	require.NoError(t, err)
+	require.Equal(t, 30, days)
+
+	require.NoError(t, p.WriteConfigField(KeyExpiryWarnDaysName, "soon"))
+	_, err = p.GetKeyExpiryWarnDays()
+	require.EqualError(t, err, "key_expiry_warn_days value not supported: soon")
+}
+
+func TestAPIKeyFingerprint(t *testing.T) {
+	fingerprint := APIKeyFingerprint("sk_test_1234567890abcd")
+	require.Len(t, fingerprint, 8)
+	require.Equal(t, fingerprint, APIKeyFingerprint("sk_test_1234567890abcd"))
+	require.NotContains(t, fingerprint, "abcd")
+
+	require.NotEqual(t, fingerprint, APIKeyFingerprint("sk_test_1234567890abce"))
+}
+
+func TestCreateProfileConcurrent(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			p := Profile{
+				ProfileName:    fmt.Sprintf("profile%d", i),
+				DeviceName:     fmt.Sprintf("device%d", i),
+				TestModeAPIKey: fmt.Sprintf("sk_test_%d", i),
+			}
+			require.NoError(t, p.CreateProfile())
+		}(i)
+	}
+	wg.Wait()
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, fmt.Sprintf("device%d", i), v.GetString(fmt.Sprintf("profile%d.device_name", i)))
+		require.Equal(t, fmt.Sprintf("sk_test_%d", i), v.GetString(fmt.Sprintf("profile%d.test_mode_api_key", i)))
+	}
+}
+
+func TestCreateProfileWriteFailureKeepsOriginal(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	p := Profile{
+		ProfileName:    "default",
+		DeviceName:     "st-testing",
+		TestModeAPIKey: "sk_test_123",
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	require.NoError(t, p.CreateProfile())
+	original := helperLoadBytes(t, profilesFile)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(profilesFile)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	renameFile = func(oldpath, newpath string) error {
+		return errors.New("disk full")
+	}
+	defer func() { renameFile = os.Rename }()
+
+	p.TestModeAPIKey = "sk_test_456"
+	require.EqualError(t, p.CreateProfile(), "disk full")
+	require.Equal(t, original, helperLoadBytes(t, profilesFile))
+
+	// The temporary file is removed
+	entries, err := os.ReadDir(filepath.Dir(profilesFile))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.Contains(t, []string{"config.toml", "config.toml.lock"}, entry.Name())
+	}
+}
+
+func TestWriteConfigFieldPreservesComments(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	original := `# Stripe CLI profiles
+# managed by hand
+
+color = 'on'
+schema_version = 1
+
+# Main test account
+[default]
+device_name = 'st-testing'
+
+# Staging account
+# do not use for demos
+[staging]
+test_mode_api_key = 'sk_test_123'
+`
+	require.NoError(t, os.WriteFile(profilesFile, []byte(original), 0600))
+
+	p := Profile{
+		ProfileName: "default",
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	require.NoError(t, p.WriteConfigField(AccountIDName, "acct_123"))
+
+	require.Equal(t, `# Stripe CLI profiles
+# managed by hand
+
+color = 'on'
+schema_version = 1
+
+# Main test account
+[default]
+account_id = 'acct_123'
+device_name = 'st-testing'
+
+# Staging account
+# do not use for demos
+[staging]
+test_mode_api_key = 'sk_test_123'
+`, string(helperLoadBytes(t, profilesFile)))
+}
+
+func TestWriteConfigFieldSkipsBoundFlags(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[default]\ndevice_name = 'st-testing'\n"), 0600))
+
+	p := Profile{
+		ProfileName: "default",
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	flags := pflag.NewFlagSet("stripe", pflag.ContinueOnError)
+	flags.String("project-name", "default", "")
+	require.NoError(t, viper.BindPFlag("project-name", flags.Lookup("project-name")))
+
+	require.NoError(t, p.WriteConfigField("color", "off"))
+	require.NoError(t, p.DeleteConfigField("color"))
+	require.NoError(t, p.SetKeyExpiryWarnDays(14))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.False(t, v.IsSet("project-name"))
+	require.Equal(t, 14, v.GetInt("default.key_expiry_warn_days"))
+}
+
+func TestDefaultSectionInherited(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	content := `[default]
+color = 'off'
+device_name = 'shared-device'
+test_mode_api_key = 'sk_test_default'
+
+[child]
+account_id = 'acct_child'
+
+[override]
+color = 'on'
+device_name = 'override-device'
+`
+	require.NoError(t, os.WriteFile(profilesFile, []byte(content), 0600))
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "child"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	child := Profile{ProfileName: "child"}
+	color, err := child.GetColor()
+	require.NoError(t, err)
+	require.Equal(t, ColorOff, color)
+	deviceName, err := child.GetDeviceName()
+	require.NoError(t, err)
+	require.Equal(t, "shared-device", deviceName)
+
+	// Keys are never inherited from the default section
+	_, err = child.GetStoredAPIKey(false)
+	require.Equal(t, validators.ErrAPIKeyNotConfigured, err)
+
+	override := Profile{ProfileName: "override"}
+	color, err = override.GetColor()
+	require.NoError(t, err)
+	require.Equal(t, ColorOn, color)
+	deviceName, err = override.GetDeviceName()
+	require.NoError(t, err)
+	require.Equal(t, "override-device", deviceName)
+}
+
+type lockedKeyring struct {
+	keyring.Keyring
+}
+
+func (lockedKeyring) Keys() ([]string, error) {
+	return nil, errors.New("keychain is locked")
+}
+
+func TestGetAPIKeyLivemodeKeyringUnavailable(t *testing.T) {
+	t.Cleanup(func() { KeyRing = nil })
+
+	p := Profile{ProfileName: "tests"}
+
+	KeyRing = lockedKeyring{}
+	_, err := p.GetAPIKey(true)
+	require.ErrorIs(t, err, ErrKeyringUnavailable)
+	require.EqualError(t, err, "the keyring is unavailable: keychain is locked")
+
+	KeyRing = nil
+	_, err = p.GetAPIKey(true)
+	require.ErrorIs(t, err, ErrKeyringUnavailable)
+
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+	_, err = p.GetAPIKey(true)
+	require.Equal(t, validators.ErrAPIKeyNotConfigured, err)
+}
+
+func TestGetExpiresAtFormats(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	p := Profile{ProfileName: "tests"}
+	expected := time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	for _, value := range []string{"2026-03-04", "2026-03-04T00:00:00Z", "2026/03/04", "03/04/2026"} {
+		viper.Set(p.GetConfigField(TestModeKeyExpiresAtName), value)
+
+		expiresAt, err := p.GetExpiresAt(false)
+		require.NoError(t, err, value)
+		require.True(t, expected.Equal(expiresAt), value)
+	}
+
+	viper.Set(p.GetConfigField(TestModeKeyExpiresAtName), "4 March 2026")
+	_, err := p.GetExpiresAt(false)
+	require.EqualError(t, err, "test_mode_key_expires_at value not supported: 4 March 2026")
+}
+
+func TestGetAPIKeyWithSource(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[tests]\ntest_mode_api_key = 'sk_test_from_config'\n"), 0600))
+
+	p := Profile{ProfileName: "tests"}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	t.Setenv("STRIPE_API_KEY", "")
+	key, source, err := p.GetAPIKeyWithSource(false)
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_from_config", key)
+	require.Equal(t, ValueSource{Source: SourceConfig}, source)
+
+	t.Setenv("STRIPE_API_KEY", "sk_test_from_env")
+	key, source, err = p.GetAPIKeyWithSource(false)
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_from_env", key)
+	require.Equal(t, ValueSource{Source: SourceEnv, ShadowsConfig: true}, source)
+
+	t.Setenv("STRIPE_API_KEY", "")
+	p.APIKey = "sk_test_from_flag"
+	key, source, err = p.GetAPIKeyWithSource(false)
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_from_flag", key)
+	require.Equal(t, ValueSource{Source: SourceFlag, ShadowsConfig: true}, source)
+}
+
+func TestGetAPIKeyLiveKeyInTestSlot(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("STRIPE_API_KEY", "")
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[tests]\ntest_mode_api_key = 'sk_live_1234567890'\n"), 0600))
+
+	p := Profile{ProfileName: "tests"}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	hook := test.NewGlobal()
+	t.Cleanup(hook.Reset)
+
+	key, err := p.GetAPIKey(false)
+	require.NoError(t, err)
+	require.Equal(t, "sk_live_1234567890", key)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	require.Equal(t, log.WarnLevel, entry.Level)
+	require.Equal(t, "test_mode_api_key appears to be a LIVE key! Commands will run against live data.", entry.Message)
+}
+
+func setupClearProfile(t *testing.T) (Profile, string) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	p := Profile{
+		ProfileName:            "default",
+		DeviceName:             "st-testing",
+		AccountID:              "acct_123",
+		TestModeAPIKey:         "sk_test_1234567890abcd",
+		TestModePublishableKey: "pk_test_1234567890abcd",
+		LiveModeAPIKey:         "rk_live_0987654321wxyz",
+		LiveModePublishableKey: "pk_live_0987654321wxyz",
+		UseKeyring:             true,
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	require.NoError(t, p.CreateProfile())
+
+	return p, profilesFile
+}
+
+func TestProfileClear(t *testing.T) {
+	testmode := false
+	livemode := true
+
+	tests := []struct {
+		name      string
+		livemode  *bool
+		clearTest bool
+		clearLive bool
+	}{
+		{name: "test only", livemode: &testmode, clearTest: true},
+		{name: "live only", livemode: &livemode, clearLive: true},
+		{name: "both", livemode: nil, clearTest: true, clearLive: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, profilesFile := setupClearProfile(t)
+
+			require.NoError(t, p.Clear(tt.livemode))
+
+			v := viper.New()
+			v.SetConfigFile(profilesFile)
+			require.NoError(t, v.ReadInConfig())
+
+			for _, field := range []string{TestModeAPIKeyName, TestModePubKeyName, TestModeKeyExpiresAtName} {
+				require.Equal(t, !tt.clearTest, v.IsSet("default."+field), field)
+			}
+
+			for _, field := range []string{LiveModeAPIKeyName, LiveModePubKeyName, LiveModeKeyExpiresAtName} {
+				require.Equal(t, !tt.clearLive, v.IsSet("default."+field), field)
+			}
+
+			require.Equal(t, "st-testing", v.GetString("default.device_name"))
+			require.Equal(t, "acct_123", v.GetString("default.account_id"))
+
+			_, err := KeyRing.Get("default.test_mode_api_key")
+			require.Equal(t, tt.clearTest, err == keyring.ErrKeyNotFound)
+
+			_, err = KeyRing.Get("default.live_mode_api_key")
+			require.Equal(t, tt.clearLive, err == keyring.ErrKeyNotFound)
+		})
+	}
+}
+
+func TestProfileClearWriteFailureRestoresKeyring(t *testing.T) {
+	p, profilesFile := setupClearProfile(t)
+	original := helperLoadBytes(t, profilesFile)
+
+	renameFile = func(oldpath, newpath string) error {
+		return errors.New("disk full")
+	}
+	defer func() { renameFile = os.Rename }()
+
+	require.EqualError(t, p.Clear(nil), "disk full")
+	require.Equal(t, original, helperLoadBytes(t, profilesFile))
+
+	item, err := KeyRing.Get("default.test_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", string(item.Data))
+
+	item, err = KeyRing.Get("default.live_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_0987654321wxyz", string(item.Data))
+}
+
+func TestReplaceLiveModeAPIKeyWriteFailureRestoresKey(t *testing.T) {
+	p, profilesFile := setupClearProfile(t)
+	original := helperLoadBytes(t, profilesFile)
+
+	renameFile = func(oldpath, newpath string) error {
+		return errors.New("disk full")
+	}
+	defer func() { renameFile = os.Rename }()
+
+	require.EqualError(t, p.ReplaceLiveModeAPIKey("rk_live_1111111111aaaa"), "disk full")
+	require.Equal(t, original, helperLoadBytes(t, profilesFile))
+
+	item, err := KeyRing.Get("default.live_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_0987654321wxyz", string(item.Data))
+}
+
+func TestSetAPIKey(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	p := Profile{ProfileName: "default"}
+	readKey := func() string {
+		v := viper.New()
+		v.SetConfigFile(profilesFile)
+		require.NoError(t, v.ReadInConfig())
+
+		return v.GetString("default.test_mode_api_key")
+	}
+
+	// Keyring storage keeps only the redacted key in the file
+	require.NoError(t, p.SetAPIKey(false, "sk_test_1234567890abcd", StorageKeyring))
+	require.Equal(t, "sk_test_**********abcd", readKey())
+
+	item, err := KeyRing.Get("default.test_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", string(item.Data))
+
+	// File storage removes the keyring copy
+	require.NoError(t, p.SetAPIKey(false, "sk_test_0987654321wxyz", StorageFile))
+	require.Equal(t, "sk_test_0987654321wxyz", readKey())
+
+	_, err = KeyRing.Get("default.test_mode_api_key")
+	require.Equal(t, keyring.ErrKeyNotFound, err)
+
+	// And keyring storage replaces the key in the file
+	require.NoError(t, p.SetAPIKey(false, "sk_test_1234567890abcd", StorageKeyring))
+	require.Equal(t, "sk_test_**********abcd", readKey())
+
+	key, err := p.GetStoredAPIKey(false)
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", key)
+}
+
+func TestSetAPIKeyLivemode(t *testing.T) {
+	_, profilesFile := setupClearProfile(t)
+	p := Profile{ProfileName: "default"}
+
+	require.EqualError(t, p.SetAPIKey(true, "rk_live_1111111111aaaa", StorageFile), "live mode keys can only be stored in the keyring")
+
+	require.NoError(t, p.SetAPIKey(true, "rk_live_1111111111aaaa", StorageKeyring))
+
+	item, err := KeyRing.Get("default.live_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_1111111111aaaa", string(item.Data))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "rk_live_**********aaaa", v.GetString("default.live_mode_api_key"))
+	require.Equal(t, "st-testing", v.GetString("default.device_name"))
+}
+
+func TestAPIVersion(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	p := Profile{
+		ProfileName: "pinned",
+		DeviceName:  "st-testing",
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+	require.NoError(t, p.CreateProfile())
+
+	require.Empty(t, p.GetAPIVersion())
+
+	require.NoError(t, p.SetAPIVersion("2024-06-20"))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "2024-06-20", v.GetString("pinned.api_version"))
+	require.Equal(t, "st-testing", v.GetString("pinned.device_name"))
+
+	require.Equal(t, "2024-06-20", p.GetAPIVersion())
+
+	require.EqualError(t, p.SetAPIVersion("2024-6-20"), "api_version must be formatted as YYYY-MM-DD: 2024-6-20")
+	require.Equal(t, "2024-06-20", p.GetAPIVersion())
+}
+
+func TestStripeAccount(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	p := Profile{
+		ProfileName: "agency",
+		DeviceName:  "st-testing",
+	}
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      p,
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+	require.NoError(t, p.CreateProfile())
+
+	require.Empty(t, p.GetStripeAccount())
+
+	require.NoError(t, p.SetStripeAccount("acct_connected"))
+	require.Equal(t, "acct_connected", p.GetStripeAccount())
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.Equal(t, "acct_connected", v.GetString("agency.stripe_account"))
+
+	require.EqualError(t, p.SetStripeAccount("connected"), "connected is not an account ID, it should start with acct_")
+	require.Equal(t, "acct_connected", p.GetStripeAccount())
+}
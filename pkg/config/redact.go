@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Redactor hides secret values, such as API keys, before they are displayed
+type Redactor interface {
+	Redact(secret string) string
+}
+
+// FullRedactor redacts secrets with RedactAPIKey, which is the default way
+// API keys are displayed by the CLI
+type FullRedactor struct{}
+
+// Redact redacts the secret with RedactAPIKey. Secrets too short to be API
+// keys are entirely replaced by "*" characters.
+func (FullRedactor) Redact(secret string) string {
+	if len(secret) < 12 {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return RedactAPIKey(secret)
+}
+
+// PartialRedactor redacts secrets but keeps the first Prefix and last Suffix
+// characters visible
+type PartialRedactor struct {
+	Prefix int
+	Suffix int
+}
+
+// Redact replaces everything but the first Prefix and last Suffix characters
+// of the secret by "*" characters. Secrets that would be left unredacted are
+// entirely replaced.
+func (r PartialRedactor) Redact(secret string) string {
+	if r.Prefix < 0 || r.Suffix < 0 || r.Prefix+r.Suffix >= len(secret) {
+		return strings.Repeat("*", len(secret))
+	}
+
+	var b strings.Builder
+
+	b.WriteString(secret[0:r.Prefix])
+	b.WriteString(strings.Repeat("*", len(secret)-r.Prefix-r.Suffix))
+	b.WriteString(secret[len(secret)-r.Suffix:])
+
+	return b.String()
+}
+
+// FingerprintRedactor replaces secrets by their fingerprint, see
+// APIKeyFingerprint
+type FingerprintRedactor struct{}
+
+// Redact returns the fingerprint of the secret
+func (FingerprintRedactor) Redact(secret string) string {
+	return APIKeyFingerprint(secret)
+}
+
+// GetRedactor returns the redactor for the given strategy name: "full",
+// "partial" (keeping the last 4 characters) or "fingerprint"
+func GetRedactor(name string) (Redactor, error) {
+	switch name {
+	case "", "full":
+		return FullRedactor{}, nil
+	case "partial":
+		return PartialRedactor{Suffix: 4}, nil
+	case "fingerprint":
+		return FingerprintRedactor{}, nil
+	default:
+		return nil, fmt.Errorf("redaction value not supported: %s. Expected one of full, partial, fingerprint", name)
+	}
+}
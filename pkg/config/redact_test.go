@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullRedactor(t *testing.T) {
+	r := FullRedactor{}
+	require.Equal(t, RedactAPIKey("sk_test_1234567890abcd"), r.Redact("sk_test_1234567890abcd"))
+	require.Equal(t, "sk_test_**********abcd", r.Redact("sk_test_1234567890abcd"))
+	require.Equal(t, "*****", r.Redact("short"))
+}
+
+func TestPartialRedactor(t *testing.T) {
+	r := PartialRedactor{Suffix: 4}
+	require.Equal(t, "******************abcd", r.Redact("sk_test_1234567890abcd"))
+
+	r = PartialRedactor{Prefix: 3, Suffix: 2}
+	require.Equal(t, "sk_*****************cd", r.Redact("sk_test_1234567890abcd"))
+
+	require.Equal(t, "****", r.Redact("abcd"))
+}
+
+func TestFingerprintRedactor(t *testing.T) {
+	r := FingerprintRedactor{}
+	require.Equal(t, APIKeyFingerprint("sk_test_1234567890abcd"), r.Redact("sk_test_1234567890abcd"))
+	require.NotContains(t, r.Redact("sk_test_1234567890abcd"), "sk_test_")
+}
+
+func TestGetRedactor(t *testing.T) {
+	r, err := GetRedactor("")
+	require.NoError(t, err)
+	require.Equal(t, FullRedactor{}, r)
+
+	r, err = GetRedactor("partial")
+	require.NoError(t, err)
+	require.Equal(t, PartialRedactor{Suffix: 4}, r)
+
+	r, err = GetRedactor("fingerprint")
+	require.NoError(t, err)
+	require.Equal(t, FingerprintRedactor{}, r)
+
+	_, err = GetRedactor("none")
+	require.EqualError(t, err, "redaction value not supported: none. Expected one of full, partial, fingerprint")
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// SchemaVersionKey is the top-level config field holding the version of the
+// profiles file layout
+const SchemaVersionKey = "schema_version"
+
+// CurrentSchemaVersion is the newest profiles file layout this CLI
+// understands. Bump it when fields are added that older CLIs would misread.
+const CurrentSchemaVersion = 1
+
+// setSchemaVersion records the layout written by this CLI, without lowering
+// the version of a file written by a newer one
+func setSchemaVersion(v *viper.Viper) {
+	if v.GetInt(SchemaVersionKey) < CurrentSchemaVersion {
+		v.Set(SchemaVersionKey, CurrentSchemaVersion)
+	}
+}
+
+// warnNewerSchemaVersion warns when the profiles file was written by a newer
+// CLI, as some of its fields may be ignored or misread
+func warnNewerSchemaVersion(v *viper.Viper) bool {
+	version := v.GetInt(SchemaVersionKey)
+	if version <= CurrentSchemaVersion {
+		return false
+	}
+
+	log.WithFields(log.Fields{
+		"prefix": "config.Config.InitConfig",
+		"path":   v.ConfigFileUsed(),
+	}).Warnf("The profiles file uses schema version %d but this version of the CLI only understands up to %d. Some settings may be ignored, consider upgrading the CLI.", version, CurrentSchemaVersion)
+
+	return true
+}
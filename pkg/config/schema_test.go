@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSetsSchemaVersion(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("[default]\ndevice_name = 'st-testing'\n"), 0600))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.NoError(t, writeConfigAtomic(v))
+
+	written := viper.New()
+	written.SetConfigFile(profilesFile)
+	require.NoError(t, written.ReadInConfig())
+	require.Equal(t, CurrentSchemaVersion, written.GetInt(SchemaVersionKey))
+	require.Equal(t, "st-testing", written.GetString("default.device_name"))
+}
+
+func TestWriteKeepsNewerSchemaVersion(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("schema_version = 99\n\n[default]\ndevice_name = 'st-testing'\n"), 0600))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.NoError(t, writeConfigAtomic(v))
+
+	written := viper.New()
+	written.SetConfigFile(profilesFile)
+	require.NoError(t, written.ReadInConfig())
+	require.Equal(t, 99, written.GetInt(SchemaVersionKey))
+}
+
+func TestInitConfigWarnsOnNewerSchemaVersion(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("schema_version = 99\n\n[default]\ndevice_name = 'st-testing'\n"), 0600))
+
+	hook := test.NewGlobal()
+	t.Cleanup(hook.Reset)
+	t.Cleanup(viper.Reset)
+
+	c := &Config{
+		Color:        "auto",
+		LogLevel:     "info",
+		Profile:      Profile{ProfileName: "default"},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+
+	require.NotNil(t, hook.LastEntry())
+	require.Equal(t, log.WarnLevel, hook.LastEntry().Level)
+	require.Contains(t, hook.LastEntry().Message, "schema version 99")
+
+	// the profiles file is still read
+	require.Equal(t, "st-testing", viper.GetString("default.device_name"))
+}
+
+func TestInitConfigWarnsOnNewerSchemaVersionOutsideDefaultFolder(t *testing.T) {
+	tests := []struct {
+		name   string
+		useEnv bool
+	}{
+		{name: "config flag"},
+		{name: "STRIPE_CONFIG_FILE", useEnv: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// keep the default profiles file empty so only the explicit one can warn
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("STRIPE_CONFIG_FILE", "")
+
+			profilesFile := filepath.Join(t.TempDir(), "nested", "profiles.toml")
+			require.NoError(t, os.MkdirAll(filepath.Dir(profilesFile), 0700))
+			require.NoError(t, os.WriteFile(profilesFile, []byte("schema_version = 2\n"), 0600))
+
+			hook := test.NewGlobal()
+			t.Cleanup(hook.Reset)
+			t.Cleanup(viper.Reset)
+
+			c := &Config{
+				Color:    "auto",
+				LogLevel: "info",
+				Profile:  Profile{ProfileName: "default"},
+			}
+			if tt.useEnv {
+				t.Setenv("STRIPE_CONFIG_FILE", profilesFile)
+			} else {
+				c.ProfilesFile = profilesFile
+			}
+			c.InitConfig()
+
+			require.Equal(t, profilesFile, viper.ConfigFileUsed())
+			require.NotNil(t, hook.LastEntry())
+			require.Equal(t, log.WarnLevel, hook.LastEntry().Level)
+			require.Contains(t, hook.LastEntry().Message, "schema version 2")
+			require.Equal(t, profilesFile, hook.LastEntry().Data["path"])
+		})
+	}
+}
+
+func TestInitConfigCurrentSchemaVersion(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(profilesFile, []byte("schema_version = 1\n"), 0600))
+
+	v := viper.New()
+	v.SetConfigFile(profilesFile)
+	require.NoError(t, v.ReadInConfig())
+	require.False(t, warnNewerSchemaVersion(v))
+}
@@ -439,7 +439,7 @@ func (fxt *Fixture) updateEnv(env map[string]string) error {
 		return err
 	}
 
-	dotenv, err := godotenv.Parse(file)
+	dotenv, err := parsers.ParseDotEnv(file)
 	if err != nil {
 		return err
 	}
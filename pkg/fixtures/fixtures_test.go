@@ -368,6 +368,23 @@ CUST_ID="char_12345"`
 	assert.Equal(t, expected, string(output))
 }
 
+func TestUpdateEnvMalformed(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	fxt := Fixture{
+		Fs: fs,
+		Responses: map[string]gjson.Result{
+			"char_bender": gjson.Parse(`{"id": "char_12345"}`),
+		},
+	}
+
+	wd, _ := os.Getwd()
+	fs.MkdirAll(wd, os.ModePerm)
+	afero.WriteFile(fs, filepath.Join(wd, ".env"), []byte("STRIPE_KEY=sk_test_12345\nSTRIPE_SECRET=\"whsec_12345\n"), os.ModePerm)
+
+	err := fxt.updateEnv(map[string]string{"CHAR_ID": "${char_bender:id}"})
+	require.EqualError(t, err, "invalid syntax on line 2 (key STRIPE_SECRET)")
+}
+
 func TestExecuteReturnsRequestNames(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
@@ -3,15 +3,46 @@ package acct
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/stripe/stripe-cli/pkg/stripe"
 )
 
+const (
+	// maxResponseBodySize is the size the account response is read up to
+	maxResponseBodySize = 1 << 20
+
+	// maxErrorBodySize is the size of the response excerpt included in errors
+	maxErrorBodySize = 200
+)
+
 // Account is the most outer layer of the json response from Stripe
 type Account struct {
-	ID       string   `json:"id"`
-	Settings Settings `json:"settings"`
+	ID              string          `json:"id"`
+	Country         string          `json:"country"`
+	ChargesEnabled  bool            `json:"charges_enabled"`
+	BusinessProfile BusinessProfile `json:"business_profile"`
+	Settings        Settings        `json:"settings"`
+}
+
+// DisplayName returns the display name of the account in the Dashboard, or
+// else its business name. It's empty when neither is set, callers fall back
+// to the account ID.
+func (a *Account) DisplayName() string {
+	if a.Settings.Dashboard.DisplayName != "" {
+		return a.Settings.Dashboard.DisplayName
+	}
+
+	return a.BusinessProfile.Name
+}
+
+// BusinessProfile is within the Account json response from Stripe
+type BusinessProfile struct {
+	Name string `json:"name"`
 }
 
 // Settings is within the Account json response from Stripe
@@ -24,19 +55,52 @@ type Dashboard struct {
 	DisplayName string `json:"display_name"`
 }
 
+// RequestOptions configures the request retrieving the account
+type RequestOptions struct {
+	// APIVersion is sent as the Stripe-Version header unless it's empty
+	APIVersion string
+
+	// StripeAccount is sent as the Stripe-Account header unless it's empty,
+	// to retrieve a connected account
+	StripeAccount string
+
+	// WrapTransport decorates the transport sending the request unless it's nil
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+}
+
 // GetUserAccount retrieves the account information
 func GetUserAccount(ctx context.Context, baseURL string, apiKey string) (*Account, error) {
+	return GetUserAccountWithOptions(ctx, baseURL, apiKey, RequestOptions{})
+}
+
+// GetUserAccountWithOptions retrieves the account information, configuring
+// the request with opts
+func GetUserAccountWithOptions(ctx context.Context, baseURL string, apiKey string, opts RequestOptions) (*Account, error) {
 	parsedBaseURL, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, err
 	}
 
 	client := &stripe.Client{
-		BaseURL: parsedBaseURL,
-		APIKey:  apiKey,
+		BaseURL:       parsedBaseURL,
+		APIKey:        apiKey,
+		WrapTransport: opts.WrapTransport,
+		Timeout:       stripe.DefaultTimeout,
 	}
 
-	resp, err := client.PerformRequest(ctx, "GET", "/v1/account", "", nil)
+	configure := func(req *http.Request) error {
+		if opts.APIVersion != "" {
+			req.Header.Set("Stripe-Version", opts.APIVersion)
+		}
+
+		if opts.StripeAccount != "" {
+			req.Header.Set("Stripe-Account", opts.StripeAccount)
+		}
+
+		return nil
+	}
+
+	resp, err := client.PerformRequest(ctx, "GET", "/v1/account", "", configure)
 
 	if err != nil {
 		return nil, err
@@ -44,12 +108,59 @@ func GetUserAccount(ctx context.Context, baseURL string, apiKey string) (*Accoun
 
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	// Proxies and gateways may answer with HTML error pages, which would
+	// otherwise surface as confusing decoding errors
+	if len(body) > 0 && !json.Valid(body) {
+		return nil, unexpectedResponseError(resp, body)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp, body)
+	}
+
 	account := &Account{}
 
-	err = json.NewDecoder(resp.Body).Decode(account)
+	err = json.Unmarshal(body, account)
 	if err != nil {
 		return nil, err
 	}
 
 	return account, nil
 }
+
+// statusError describes an error response, including the message of the
+// Stripe error it holds if any
+func statusError(resp *http.Response, body []byte) error {
+	var errorBody struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if json.Unmarshal(body, &errorBody) == nil && errorBody.Error.Message != "" {
+		return fmt.Errorf("failed to retrieve the account: %s: %s", resp.Status, errorBody.Error.Message)
+	}
+
+	return fmt.Errorf("failed to retrieve the account: %s", resp.Status)
+}
+
+// unexpectedResponseError describes a response that isn't JSON, including at
+// most maxErrorBodySize bytes of its body
+func unexpectedResponseError(resp *http.Response, body []byte) error {
+	excerpt := strings.Join(strings.Fields(string(body)), " ")
+	if len(excerpt) > maxErrorBodySize {
+		excerpt = excerpt[:maxErrorBodySize] + "..."
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "none"
+	}
+
+	return fmt.Errorf("unexpected response from %s (status %d, content-type %s): %s", resp.Request.URL, resp.StatusCode, contentType, excerpt)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -41,6 +42,40 @@ func TestGetAccount(t *testing.T) {
 	)
 }
 
+func TestGetAccountAPIVersion(t *testing.T) {
+	versions := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions = append(versions, r.Header.Get("Stripe-Version"))
+		require.Empty(t, r.Header.Get("Stripe-Account"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&Account{ID: "acct_123"})
+	}))
+	defer ts.Close()
+
+	_, err := GetUserAccountWithOptions(context.Background(), ts.URL, "sk_test_123", RequestOptions{APIVersion: "2024-06-20"})
+	require.NoError(t, err)
+
+	_, err = GetUserAccount(context.Background(), ts.URL, "sk_test_123")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"2024-06-20", ""}, versions)
+}
+
+func TestGetAccountStripeAccount(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "acct_connected", r.Header.Get("Stripe-Account"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&Account{ID: "acct_connected"})
+	}))
+	defer ts.Close()
+
+	acc, err := GetUserAccountWithOptions(context.Background(), ts.URL, "sk_test_123", RequestOptions{StripeAccount: "acct_connected"})
+	require.NoError(t, err)
+	require.Equal(t, "acct_connected", acc.ID)
+}
+
 func TestGetAccountNoDisplayName(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		require.Equal(t, "GET", r.Method)
@@ -68,3 +103,111 @@ func TestGetAccountNoDisplayName(t *testing.T) {
 		acc.ID,
 	)
 }
+
+func TestGetAccountDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "acct_123", "country": "US", "charges_enabled": true, "settings": {"dashboard": {"display_name": "test_name"}}}`))
+	}))
+	defer ts.Close()
+
+	acc, err := GetUserAccount(context.Background(), ts.URL, "sk_test_123")
+	require.NoError(t, err)
+	require.Equal(t, "acct_123", acc.ID)
+	require.Equal(t, "US", acc.Country)
+	require.True(t, acc.ChargesEnabled)
+	require.Equal(t, testName, acc.DisplayName())
+}
+
+func TestGetAccountErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Invalid API Key provided"}}`))
+	}))
+	defer ts.Close()
+
+	_, err := GetUserAccount(context.Background(), ts.URL, "sk_test_123")
+	require.EqualError(t, err, "failed to retrieve the account: 401 Unauthorized: Invalid API Key provided")
+}
+
+func TestGetAccountErrorStatusWithoutMessage(t *testing.T) {
+	for _, body := range []string{"", `{}`, `{"error": "forbidden"}`} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(body))
+		}))
+
+		_, err := GetUserAccount(context.Background(), ts.URL, "sk_test_123")
+		require.EqualError(t, err, "failed to retrieve the account: 403 Forbidden", body)
+
+		ts.Close()
+	}
+}
+
+func TestGetAccountHTMLResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>\n<body>" + strings.Repeat("Bad gateway. ", 100) + "</body>\n</html>"))
+	}))
+	defer ts.Close()
+
+	_, err := GetUserAccount(context.Background(), ts.URL, "sk_test_123")
+	require.Error(t, err)
+
+	prefix := "unexpected response from " + ts.URL + "/v1/account (status 502, content-type text/html): <html> <body>Bad gateway."
+	require.True(t, strings.HasPrefix(err.Error(), prefix), err.Error())
+	require.True(t, strings.HasSuffix(err.Error(), "..."))
+	require.Less(t, len(err.Error()), len(prefix)+maxErrorBodySize)
+}
+
+func TestDisplayName(t *testing.T) {
+	account := &Account{ID: "acct_123"}
+	account.Settings.Dashboard.DisplayName = testName
+	account.BusinessProfile.Name = "business_name"
+
+	require.Equal(t, testName, account.DisplayName())
+}
+
+func TestDisplayNameBusinessProfile(t *testing.T) {
+	account := &Account{ID: "acct_123"}
+	account.BusinessProfile.Name = "business_name"
+
+	require.Equal(t, "business_name", account.DisplayName())
+}
+
+func TestDisplayNameNoName(t *testing.T) {
+	account := &Account{ID: "acct_123"}
+
+	require.Equal(t, "", account.DisplayName())
+}
+
+func TestDisplayNameGetAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"settings", `{"id": "acct_123", "settings": {"dashboard": {"display_name": "test_name"}}, "business_profile": {"name": "business_name"}}`, testName},
+		{"business profile", `{"id": "acct_123", "settings": {"dashboard": {"display_name": null}}, "business_profile": {"name": "business_name"}}`, "business_name"},
+		{"no name", `{"id": "acct_123", "settings": {"dashboard": {"display_name": null}}, "business_profile": null}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "GET", r.Method)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			acc, err := GetUserAccount(context.Background(), ts.URL, "sk_test_123")
+			require.NoError(t, err)
+			require.Equal(t, "acct_123", acc.ID)
+			require.Equal(t, tt.expected, acc.DisplayName())
+		})
+	}
+}
@@ -0,0 +1,525 @@
+package login
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	exec "golang.org/x/sys/execabs"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/login/acct"
+	"github.com/stripe/stripe-cli/pkg/stripe"
+	"github.com/stripe/stripe-cli/pkg/validators"
+)
+
+// AccountFetcher retrieves the account an API key belongs to
+type AccountFetcher interface {
+	GetAccount(ctx context.Context, apiKey string) (*acct.Account, error)
+}
+
+// ConnectedAccountFetcher retrieves a connected account of the platform an API
+// key belongs to
+type ConnectedAccountFetcher interface {
+	GetConnectedAccount(ctx context.Context, apiKey string, stripeAccount string) (*acct.Account, error)
+}
+
+// HTTPAccountFetcher retrieves the account from the Stripe API
+type HTTPAccountFetcher struct {
+	// BaseURL of the Stripe API, defaults to stripe.DefaultAPIBaseURL
+	BaseURL string
+
+	// WrapTransport decorates the transport of stripe.Client, for example
+	// with stripe.WithTrace to log the request.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	// APIVersion is sent as the Stripe-Version header unless it's empty
+	APIVersion string
+}
+
+// NewHTTPAccountFetcher returns an HTTPAccountFetcher sending the API version
+// the profile is pinned to
+func NewHTTPAccountFetcher(profile *config.Profile) *HTTPAccountFetcher {
+	return &HTTPAccountFetcher{APIVersion: profile.GetAPIVersion()}
+}
+
+// GetAccount retrieves the account the API key belongs to
+func (f *HTTPAccountFetcher) GetAccount(ctx context.Context, apiKey string) (*acct.Account, error) {
+	return f.GetConnectedAccount(ctx, apiKey, "")
+}
+
+// GetConnectedAccount retrieves the connected account stripeAccount by sending
+// it as the Stripe-Account header, or the account the API key belongs to when
+// it's empty
+func (f *HTTPAccountFetcher) GetConnectedAccount(ctx context.Context, apiKey string, stripeAccount string) (*acct.Account, error) {
+	baseURL := f.BaseURL
+	if baseURL == "" {
+		baseURL = stripe.DefaultAPIBaseURL
+	}
+
+	return acct.GetUserAccountWithOptions(ctx, baseURL, apiKey, acct.RequestOptions{
+		APIVersion:    f.APIVersion,
+		StripeAccount: stripeAccount,
+		WrapTransport: f.WrapTransport,
+	})
+}
+
+// APIKeyLoginOptions configures how LoginWithAPIKey behaves
+type APIKeyLoginOptions struct {
+	// Fetcher retrieves the account the key belongs to. Defaults to an
+	// HTTPAccountFetcher.
+	Fetcher AccountFetcher
+
+	// Force replaces a different key already stored for the profile without
+	// asking for confirmation, even if it belongs to a different account.
+	Force bool
+
+	// NoInput disables prompts, so replacing an existing key requires Force.
+	NoInput bool
+
+	// DryRun validates the key and looks up its account, then prints what
+	// would be written instead of writing the profile.
+	DryRun bool
+
+	// JSON prints the DryRun plan as JSON.
+	JSON bool
+
+	// IdentityOnly only looks up the account the key belongs to and prints
+	// it as JSON, without checking or writing the profile.
+	IdentityOnly bool
+
+	// Keyring stores the key in the keyring rather than in the profiles file.
+	// Live mode keys are always stored in the keyring.
+	Keyring bool
+
+	// Mode is the mode the key must belong to, "test" or "live", and selects
+	// where it is stored in the profile. Defaults to the mode of the key.
+	Mode string
+
+	// Verify fails the login, without writing the profile, when the account
+	// the key belongs to can't be retrieved. By default the key is saved
+	// anyway and the error is only printed.
+	Verify bool
+
+	// Hostname resolves the device name when none is configured and
+	// STRIPE_DEVICE_NAME isn't set. Defaults to os.Hostname.
+	Hostname func() (string, error)
+
+	// DeviceFromGit prefixes the hostname with the git user name, e.g.
+	// "alice@laptop", when resolving the device name.
+	DeviceFromGit bool
+
+	// StripeAccount is a connected account stored in the profile. Access to
+	// it is verified when the Fetcher is a ConnectedAccountFetcher. Defaults
+	// to the connected account already stored.
+	StripeAccount string
+
+	// ReplaceLiveKey rotates the live mode key stored in the keyring: the new
+	// key is verified, then stored before the old one is dropped, and the
+	// rest of the profile is left untouched. Implies Verify.
+	ReplaceLiveKey bool
+
+	// GitUserName resolves the git user name for DeviceFromGit. Defaults to
+	// `git config user.name`.
+	GitUserName func() (string, error)
+
+	// AuditLog is the path of the JSON lines file logins are appended to.
+	// Defaults to audit.log in the folder of the profiles file.
+	AuditLog string
+
+	// AuditFailures also appends failed logins to the audit log.
+	AuditFailures bool
+
+	// Input is where confirmations are read from. Defaults to os.Stdin.
+	Input io.Reader
+
+	// Output is where messages are written to. Defaults to os.Stdout.
+	Output io.Writer
+}
+
+// LoginPlan describes the profile LoginWithAPIKey writes
+type LoginPlan struct {
+	ProjectName         string `json:"project_name"`
+	Mode                string `json:"mode"`
+	AccountID           string `json:"account_id,omitempty"`
+	DisplayName         string `json:"display_name,omitempty"`
+	ReplacesExistingKey bool   `json:"replaces_existing_key"`
+}
+
+// LoginIdentity describes the account an API key belongs to
+type LoginIdentity struct {
+	AccountID   string `json:"account_id"`
+	DisplayName string `json:"display_name"`
+	Mode        string `json:"mode"`
+}
+
+// LoginWithAPIKey configures the profile with the provided API key without
+// going through the browser or interactive flows.
+func LoginWithAPIKey(ctx context.Context, config *config.Config, apiKey string, opts APIKeyLoginOptions) (err error) {
+	if opts.StripeAccount != "" {
+		if err := validators.AccountID(opts.StripeAccount); err != nil {
+			return err
+		}
+	}
+
+	if opts.Fetcher == nil {
+		opts.Fetcher = NewHTTPAccountFetcher(&config.Profile)
+	}
+
+	if opts.Input == nil {
+		opts.Input = os.Stdin
+	}
+
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	if opts.Hostname == nil {
+		opts.Hostname = os.Hostname
+	}
+
+	if opts.GitUserName == nil {
+		opts.GitUserName = gitUserName
+	}
+
+	apiKey = trimQuotes(strings.TrimSpace(apiKey))
+
+	err = validators.APIKey(apiKey)
+	if err != nil {
+		return err
+	}
+
+	mode, _, err := validators.ParseAPIKey(apiKey)
+	if err != nil {
+		return err
+	}
+
+	if opts.AuditFailures && !opts.DryRun && !opts.IdentityOnly {
+		defer func() {
+			if err != nil {
+				logAuditError(recordLogin(config, opts, apiKey, mode, err))
+			}
+		}()
+	}
+
+	if opts.Mode != "" && opts.Mode != mode {
+		return fmt.Errorf("the API key is a %s mode key, but %s mode was requested", mode, opts.Mode)
+	}
+
+	if opts.IdentityOnly {
+		return printLoginIdentity(ctx, opts, apiKey, mode)
+	}
+
+	livemode := mode == "live"
+
+	if opts.ReplaceLiveKey && !livemode {
+		return errors.New("only live mode keys can replace the live mode key stored in the keyring")
+	}
+
+	existingKey, _ := config.Profile.GetStoredAPIKey(livemode)
+
+	// The key is only written when it changes, or to move it to the keyring
+	keyUnchanged := existingKey == apiKey
+	storeKey := !keyUnchanged || (opts.Keyring && !config.Profile.IsAPIKeyInKeyring(livemode))
+
+	if opts.ReplaceLiveKey {
+		if existingKey == "" {
+			return fmt.Errorf("the %s project has no live mode key stored in the keyring to replace", config.Profile.ProfileName)
+		}
+
+		// The old key is only dropped once the new one is known to work
+		opts.Verify = true
+	}
+
+	if existingKey != "" && !keyUnchanged && !opts.Force && !opts.DryRun && !opts.ReplaceLiveKey {
+		confirmErr := confirmReplaceKey(opts, config.Profile.ProfileName)
+		if confirmErr != nil {
+			return confirmErr
+		}
+	}
+
+	// Failing to retrieve the account should not block the login, unless
+	// verification was requested or the login was canceled
+	account, accountErr := opts.Fetcher.GetAccount(ctx, apiKey)
+	if ctx.Err() != nil {
+		return fmt.Errorf("login canceled: %w", ctx.Err())
+	}
+
+	if accountErr != nil && opts.Verify {
+		return fmt.Errorf("failed to verify the API key: %w", accountErr)
+	}
+
+	// The profile keeps the account the key belongs to, the connected account
+	// is only checked to be reachable with the key
+	stripeAccount := opts.StripeAccount
+	if stripeAccount == "" {
+		stripeAccount = config.Profile.GetStripeAccount()
+	}
+
+	if connectedFetcher, ok := opts.Fetcher.(ConnectedAccountFetcher); ok && stripeAccount != "" && accountErr == nil {
+		_, connectedErr := connectedFetcher.GetConnectedAccount(ctx, apiKey, stripeAccount)
+		if ctx.Err() != nil {
+			return fmt.Errorf("login canceled: %w", ctx.Err())
+		}
+
+		if connectedErr != nil {
+			if opts.Verify {
+				return fmt.Errorf("failed to verify access to connected account %s: %w", stripeAccount, connectedErr)
+			}
+
+			fmt.Fprintf(opts.Output, "> Error verifying access to connected account %s: %s\n", stripeAccount, connectedErr)
+		}
+	}
+
+	if accountErr == nil && !opts.Force && !opts.DryRun {
+		existingAccountID, _ := config.Profile.GetAccountID()
+		if existingAccountID != "" && existingAccountID != account.ID {
+			return fmt.Errorf("the %s project is configured for account %s but the API key belongs to account %s, re-run with --force to use it anyway", config.Profile.ProfileName, existingAccountID, account.ID)
+		}
+	}
+
+	if opts.DryRun {
+		plan := LoginPlan{
+			ProjectName:         config.Profile.ProfileName,
+			Mode:                mode,
+			ReplacesExistingKey: existingKey != "" && !keyUnchanged,
+		}
+
+		if accountErr == nil {
+			plan.AccountID = account.ID
+			plan.DisplayName = account.DisplayName()
+		}
+
+		return printLoginPlan(opts, plan, accountErr)
+	}
+
+	if config.Profile.DeviceName == "" {
+		deviceName := os.Getenv("STRIPE_DEVICE_NAME")
+		if deviceName == "" {
+			deviceName = resolveDeviceName(opts)
+		}
+
+		config.Profile.DeviceName = deviceName
+	}
+
+	if accountErr == nil {
+		config.Profile.DisplayName = account.DisplayName()
+		config.Profile.AccountID = account.ID
+	}
+
+	var profileErr error
+	switch {
+	case opts.ReplaceLiveKey && storeKey:
+		profileErr = config.Profile.ReplaceLiveModeAPIKey(apiKey)
+	case opts.ReplaceLiveKey:
+		// Nothing to rotate, the stored live mode key is already this one
+	default:
+		profileErr = createProfileWithKey(&config.Profile, livemode, apiKey, opts.Keyring, storeKey)
+	}
+
+	if profileErr != nil {
+		return profileErr
+	}
+
+	if opts.StripeAccount != "" {
+		err = config.Profile.SetStripeAccount(opts.StripeAccount)
+		if err != nil {
+			return err
+		}
+	}
+
+	if keyUnchanged {
+		fmt.Fprintf(opts.Output, "> The %s project is already configured with this API key\n", config.Profile.ProfileName)
+	}
+
+	logConfiguredKey(apiKey, config.Profile.AccountID)
+	logAuditError(recordLogin(config, opts, apiKey, mode, nil))
+
+	// The '>' character is automatically included at the end of client login
+	// due to ansi spinner. Since no spinner is used when logging in with an
+	// API key, we need to include it manually to maintain consistency in outputs.
+	if accountErr != nil {
+		fmt.Fprintf(opts.Output, "> Error verifying the CLI was setup successfully: %s\n", accountErr)
+		return nil
+	}
+
+	message, err := SuccessMessage(ctx, account, "", apiKey)
+	if err != nil {
+		fmt.Fprintf(opts.Output, "> Error verifying the CLI was setup successfully: %s\n", err)
+	} else {
+		fmt.Fprintf(opts.Output, "> %s\n", message)
+	}
+
+	return nil
+}
+
+// createProfileWithKey writes the profile, then stores the API key in the
+// keyring for live mode keys or when useKeyring is set, and in the profiles
+// file otherwise. The key is left as stored unless storeKey is set.
+func createProfileWithKey(profile *config.Profile, livemode bool, apiKey string, useKeyring bool, storeKey bool) error {
+	// The key is written by SetAPIKey, which also removes it from the other
+	// storage, and the key of the other mode is kept
+	profile.LiveModeAPIKey = ""
+	profile.TestModeAPIKey = ""
+
+	err := profile.UpdateProfile()
+	if err != nil || !storeKey {
+		return err
+	}
+
+	storage := config.StorageFile
+	if livemode || useKeyring {
+		storage = config.StorageKeyring
+	}
+
+	return profile.SetAPIKey(livemode, apiKey, storage)
+}
+
+// trimQuotes removes the matching single or double quotes keys are often
+// pasted with
+func trimQuotes(apiKey string) string {
+	if len(apiKey) < 2 {
+		return apiKey
+	}
+
+	first, last := apiKey[0], apiKey[len(apiKey)-1]
+	if first != last || (first != '"' && first != '\'') {
+		return apiKey
+	}
+
+	log.WithFields(log.Fields{
+		"prefix": "login.LoginWithAPIKey",
+	}).Debug("Removed the quotes around the API key")
+
+	return strings.TrimSpace(apiKey[1 : len(apiKey)-1])
+}
+
+// resolveDeviceName returns the hostname, prefixed with the git user name when
+// DeviceFromGit is set and git is configured
+func resolveDeviceName(opts APIKeyLoginOptions) string {
+	hostname, err := opts.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	if !opts.DeviceFromGit {
+		return hostname
+	}
+
+	userName, err := opts.GitUserName()
+	if err != nil || userName == "" {
+		return hostname
+	}
+
+	return userName + "@" + hostname
+}
+
+// gitUserName returns the user name configured in git
+func gitUserName() (string, error) {
+	output, err := exec.Command("git", "config", "user.name").Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// logConfiguredKey logs which key was configured, so that scripted logins can
+// be audited. The key is always redacted.
+func logConfiguredKey(apiKey, accountID string) {
+	mode, _, _ := validators.ParseAPIKey(apiKey)
+
+	message := fmt.Sprintf("configured %s_mode key %s", mode, config.RedactAPIKey(apiKey))
+	if accountID != "" {
+		message += " for " + accountID
+	}
+
+	log.WithFields(log.Fields{
+		"prefix": "login.LoginWithAPIKey",
+	}).Info(message)
+}
+
+// logAuditError warns when the login couldn't be appended to the audit log,
+// without failing the login itself
+func logAuditError(err error) {
+	if err == nil {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"prefix": "login.LoginWithAPIKey",
+	}).Warnf("Could not append the login to the audit log: %s", err)
+}
+
+// printLoginPlan prints what a login would write to the profile
+func printLoginPlan(opts APIKeyLoginOptions, plan LoginPlan, accountErr error) error {
+	if opts.JSON {
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(plan)
+	}
+
+	fmt.Fprintln(opts.Output, "Dry run, nothing was written. Logging in would configure:")
+	fmt.Fprintf(opts.Output, "  project: %s\n", plan.ProjectName)
+	fmt.Fprintf(opts.Output, "  mode: %s\n", plan.Mode)
+
+	if accountErr != nil {
+		fmt.Fprintf(opts.Output, "  account: could not be retrieved: %s\n", accountErr)
+	} else {
+		fmt.Fprintf(opts.Output, "  account_id: %s\n", plan.AccountID)
+		fmt.Fprintf(opts.Output, "  display_name: %s\n", plan.DisplayName)
+	}
+
+	if plan.ReplacesExistingKey {
+		fmt.Fprintln(opts.Output, "  replaces the API key currently configured for the project")
+	}
+
+	return nil
+}
+
+// printLoginIdentity prints the account apiKey belongs to as JSON
+func printLoginIdentity(ctx context.Context, opts APIKeyLoginOptions, apiKey string, mode string) error {
+	account, err := opts.Fetcher.GetAccount(ctx, apiKey)
+	if ctx.Err() != nil {
+		return fmt.Errorf("login canceled: %w", ctx.Err())
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the account of the API key: %w", err)
+	}
+
+	encoder := json.NewEncoder(opts.Output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(LoginIdentity{
+		AccountID:   account.ID,
+		DisplayName: account.DisplayName(),
+		Mode:        mode,
+	})
+}
+
+// confirmReplaceKey asks the user whether the API key already stored for the
+// profile should be replaced
+func confirmReplaceKey(opts APIKeyLoginOptions, profileName string) error {
+	if opts.NoInput {
+		return fmt.Errorf("the %s project already has a different API key configured, re-run with --force to replace it", profileName)
+	}
+
+	fmt.Fprintf(opts.Output, "The %s project already has a different API key configured. Replace it? [y/N] ", profileName)
+
+	answer, _ := bufio.NewReader(opts.Input).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("login canceled, the existing API key was kept")
+	}
+}
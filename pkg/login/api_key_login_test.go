@@ -0,0 +1,691 @@
+package login
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+	"github.com/stripe/stripe-cli/pkg/login/acct"
+	"github.com/stripe/stripe-cli/pkg/stripe"
+)
+
+type fakeAccountFetcher struct {
+	account *acct.Account
+	err     error
+	calls   int
+}
+
+func (f *fakeAccountFetcher) GetAccount(ctx context.Context, apiKey string) (*acct.Account, error) {
+	f.calls++
+	return f.account, f.err
+}
+
+func setupAPIKeyLoginConfig(t *testing.T) *config.Config {
+	profilesFile := filepath.Join(t.TempDir(), "stripe", "config.toml")
+
+	c := &config.Config{
+		Color:    "auto",
+		LogLevel: "info",
+		Profile: config.Profile{
+			DeviceName:  "st-testing",
+			ProfileName: "tests",
+		},
+		ProfilesFile: profilesFile,
+	}
+	c.InitConfig()
+	config.KeyRing = keyring.NewArrayKeyring([]keyring.Item{})
+
+	t.Cleanup(viper.Reset)
+
+	return c
+}
+
+func readProfilesFile(t *testing.T, c *config.Config) *viper.Viper {
+	v := viper.New()
+	v.SetConfigFile(c.ProfilesFile)
+	require.NoError(t, v.ReadInConfig())
+
+	return v
+}
+
+func TestLoginWithAPIKeyFakeFetcher(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	account := &acct.Account{
+		ID: "acct_123",
+	}
+	account.Settings.Dashboard.DisplayName = testDisplayName
+	fetcher := &fakeAccountFetcher{account: account}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher})
+	require.NoError(t, err)
+	require.Equal(t, 1, fetcher.calls)
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "sk_test_1234567890", v.GetString("tests.test_mode_api_key"))
+	require.Equal(t, testDisplayName, v.GetString("tests.display_name"))
+	require.Equal(t, "acct_123", v.GetString("tests.account_id"))
+	require.Equal(t, "st-testing", v.GetString("tests.device_name"))
+}
+
+func TestLoginWithAPIKeyAccountLookupFails(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{err: errors.New("connection refused")}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher})
+	require.NoError(t, err)
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "sk_test_1234567890", v.GetString("tests.test_mode_api_key"))
+	require.False(t, v.IsSet("tests.account_id"))
+}
+
+func TestLoginWithAPIKeyInvalidKey(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{}
+
+	err := LoginWithAPIKey(context.Background(), c, "pk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher})
+	require.EqualError(t, err, "the CLI only supports using a secret or restricted key")
+	require.Equal(t, 0, fetcher.calls)
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyReplaceExistingKeyWithForce(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1111111111", APIKeyLoginOptions{Fetcher: fetcher})
+	require.NoError(t, err)
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_2222222222", APIKeyLoginOptions{Fetcher: fetcher, NoInput: true})
+	require.EqualError(t, err, "the tests project already has a different API key configured, re-run with --force to replace it")
+	require.Equal(t, "sk_test_1111111111", readProfilesFile(t, c).GetString("tests.test_mode_api_key"))
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_2222222222", APIKeyLoginOptions{Fetcher: fetcher, NoInput: true, Force: true})
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_2222222222", readProfilesFile(t, c).GetString("tests.test_mode_api_key"))
+}
+
+func TestLoginWithAPIKeyIdenticalKey(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1111111111", APIKeyLoginOptions{Fetcher: fetcher, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.NoError(t, c.Profile.WriteConfigField(config.TestModeKeyExpiresAtName, "2020-01-01"))
+
+	// the key is still verified and the connected account stored, but the key
+	// isn't written again, which would push back its expiry
+	out := new(bytes.Buffer)
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_1111111111", APIKeyLoginOptions{Fetcher: fetcher, NoInput: true, Verify: true, StripeAccount: "acct_456", Output: out})
+	require.NoError(t, err)
+	require.Equal(t, 2, fetcher.calls)
+	require.Contains(t, out.String(), "The tests project is already configured with this API key")
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "sk_test_1111111111", v.GetString("tests.test_mode_api_key"))
+	require.Equal(t, "2020-01-01", v.GetString("tests.test_mode_key_expires_at"))
+	require.Equal(t, "acct_456", v.GetString("tests.stripe_account"))
+}
+
+func TestLoginWithAPIKeyIdenticalKeyMovedToKeyring(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1111111111", APIKeyLoginOptions{Fetcher: fetcher, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_1111111111", APIKeyLoginOptions{Fetcher: fetcher, NoInput: true, Keyring: true, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	require.Equal(t, config.RedactAPIKey("sk_test_1111111111"), readProfilesFile(t, c).GetString("tests.test_mode_api_key"))
+
+	item, err := config.KeyRing.Get("tests.test_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1111111111", string(item.Data))
+}
+
+func TestLoginWithAPIKeyDryRun(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	account := &acct.Account{
+		ID: "acct_123",
+	}
+	account.Settings.Dashboard.DisplayName = testDisplayName
+	fetcher := &fakeAccountFetcher{account: account}
+	out := new(bytes.Buffer)
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, DryRun: true, Output: out})
+	require.NoError(t, err)
+	require.Equal(t, `Dry run, nothing was written. Logging in would configure:
+  project: tests
+  mode: test
+  account_id: acct_123
+  display_name: test_disp_name
+`, out.String())
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyDryRunJSON(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+	out := new(bytes.Buffer)
+
+	err := LoginWithAPIKey(context.Background(), c, "rk_live_1234567890", APIKeyLoginOptions{Fetcher: fetcher, DryRun: true, JSON: true, Output: out})
+	require.NoError(t, err)
+
+	var plan LoginPlan
+	require.NoError(t, json.Unmarshal(out.Bytes(), &plan))
+	require.Equal(t, LoginPlan{ProjectName: "tests", Mode: "live", AccountID: "acct_123"}, plan)
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyIdentityOnly(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	account := &acct.Account{
+		ID: "acct_123",
+	}
+	account.Settings.Dashboard.DisplayName = testDisplayName
+	fetcher := &fakeAccountFetcher{account: account}
+	out := new(bytes.Buffer)
+
+	err := LoginWithAPIKey(context.Background(), c, "rk_live_1234567890", APIKeyLoginOptions{Fetcher: fetcher, IdentityOnly: true, AuditFailures: true, Output: out})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"account_id": "acct_123", "display_name": "test_disp_name", "mode": "live"}`, out.String())
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+
+	keys, err := config.KeyRing.Keys()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestLoginWithAPIKeyIdentityOnlyLookupFails(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{err: errors.New("connection refused")}
+	out := new(bytes.Buffer)
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, IdentityOnly: true, AuditFailures: true, Output: out})
+	require.EqualError(t, err, "failed to retrieve the account of the API key: connection refused")
+	require.Empty(t, out.String())
+
+	// nothing is written, not even the audit log
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+	_, statErr = os.Stat(filepath.Join(filepath.Dir(c.ProfilesFile), AuditLogFileName))
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyLogsRedactedKey(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+	hook := test.NewGlobal()
+	t.Cleanup(hook.Reset)
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890abcd", APIKeyLoginOptions{Fetcher: fetcher, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	require.Equal(t, log.InfoLevel, entry.Level)
+	require.Equal(t, "configured test_mode key sk_test_**********abcd for acct_123", entry.Message)
+
+	for _, entry := range hook.AllEntries() {
+		require.NotContains(t, entry.Message, "sk_test_1234567890abcd")
+	}
+}
+
+func TestLoginWithAPIKeySingleAccountRequest(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/account", r.URL.Path)
+		requests++
+
+		account := &acct.Account{
+			ID: "acct_123",
+		}
+		account.Settings.Dashboard.DisplayName = testDisplayName
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(account)
+	}))
+	defer ts.Close()
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{
+		Fetcher: &HTTPAccountFetcher{BaseURL: ts.URL},
+		Output:  new(bytes.Buffer),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, testDisplayName, v.GetString("tests.display_name"))
+	require.Equal(t, "acct_123", v.GetString("tests.account_id"))
+}
+
+func TestLoginWithAPIKeySendsAPIVersion(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "2024-06-20", r.Header.Get("Stripe-Version"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&acct.Account{ID: "acct_123"})
+	}))
+	defer ts.Close()
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{
+		Fetcher: &HTTPAccountFetcher{BaseURL: ts.URL, APIVersion: "2024-06-20"},
+		Verify:  true,
+		Output:  new(bytes.Buffer),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "acct_123", readProfilesFile(t, c).GetString("tests.account_id"))
+}
+
+func TestLoginWithAPIKeyTimeout(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	defaultTimeout := stripe.DefaultTimeout
+	stripe.DefaultTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { stripe.DefaultTimeout = defaultTimeout })
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{
+		Fetcher: &HTTPAccountFetcher{BaseURL: ts.URL},
+		Verify:  true,
+		Output:  new(bytes.Buffer),
+	})
+	require.ErrorContains(t, err, "failed to verify the API key")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLoginWithAPIKeyStripeAccount(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	stripeAccounts := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stripeAccount := r.Header.Get("Stripe-Account")
+		stripeAccounts = append(stripeAccounts, stripeAccount)
+
+		account := &acct.Account{ID: "acct_platform"}
+		if stripeAccount != "" {
+			account.ID = stripeAccount
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(account)
+	}))
+	defer ts.Close()
+
+	fetcher := &HTTPAccountFetcher{BaseURL: ts.URL}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{
+		Fetcher:       fetcher,
+		StripeAccount: "acct_connected",
+		Verify:        true,
+		Output:        new(bytes.Buffer),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "acct_connected"}, stripeAccounts)
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "acct_connected", v.GetString("tests.stripe_account"))
+	require.Equal(t, "acct_platform", v.GetString("tests.account_id"))
+
+	// A later login without the connected account keeps verifying the stored one
+	stripeAccounts = []string{}
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_0987654321", APIKeyLoginOptions{
+		Fetcher: fetcher,
+		Force:   true,
+		Verify:  true,
+		Output:  new(bytes.Buffer),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "acct_connected"}, stripeAccounts)
+
+	v = readProfilesFile(t, c)
+	require.Equal(t, "acct_connected", v.GetString("tests.stripe_account"))
+	require.Equal(t, "acct_platform", v.GetString("tests.account_id"))
+}
+
+func TestLoginWithAPIKeyStripeAccountUnreachable(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Stripe-Account") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&acct.Account{ID: "acct_platform"})
+	}))
+	defer ts.Close()
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{
+		Fetcher:       &HTTPAccountFetcher{BaseURL: ts.URL},
+		StripeAccount: "acct_connected",
+		Verify:        true,
+		Output:        new(bytes.Buffer),
+	})
+	require.ErrorContains(t, err, "failed to verify access to connected account acct_connected")
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyInvalidStripeAccount(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, StripeAccount: "connected", Output: new(bytes.Buffer)})
+	require.EqualError(t, err, "connected is not an account ID, it should start with acct_")
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyAccountMismatch(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1111111111", APIKeyLoginOptions{
+		Fetcher: &fakeAccountFetcher{account: &acct.Account{ID: "acct_A"}},
+		Output:  new(bytes.Buffer),
+	})
+	require.NoError(t, err)
+
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_B"}}
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_2222222222", APIKeyLoginOptions{Fetcher: fetcher, Input: strings.NewReader("y\n"), Output: new(bytes.Buffer)})
+	require.EqualError(t, err, "the tests project is configured for account acct_A but the API key belongs to account acct_B, re-run with --force to use it anyway")
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "sk_test_1111111111", v.GetString("tests.test_mode_api_key"))
+	require.Equal(t, "acct_A", v.GetString("tests.account_id"))
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_2222222222", APIKeyLoginOptions{Fetcher: fetcher, Force: true, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.Equal(t, "acct_B", readProfilesFile(t, c).GetString("tests.account_id"))
+}
+
+func TestLoginWithAPIKeyKeyring(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890abcd", APIKeyLoginOptions{Fetcher: fetcher, Keyring: true, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	item, err := config.KeyRing.Get("tests.test_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", string(item.Data))
+
+	content, err := os.ReadFile(c.ProfilesFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "sk_test_1234567890abcd")
+	require.Equal(t, "sk_test_**********abcd", readProfilesFile(t, c).GetString("tests.test_mode_api_key"))
+
+	apiKey, err := c.Profile.GetStoredAPIKey(false)
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890abcd", apiKey)
+}
+
+func TestLoginWithAPIKeyHostnameResolver(t *testing.T) {
+	t.Setenv("STRIPE_DEVICE_NAME", "")
+
+	c := setupAPIKeyLoginConfig(t)
+	c.Profile.DeviceName = ""
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+	hostname := func() (string, error) { return "ci-runner", nil }
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Hostname: hostname, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.Equal(t, "ci-runner", readProfilesFile(t, c).GetString("tests.device_name"))
+}
+
+func TestLoginWithAPIKeyDeviceFromGit(t *testing.T) {
+	t.Setenv("STRIPE_DEVICE_NAME", "")
+
+	c := setupAPIKeyLoginConfig(t)
+	c.Profile.DeviceName = ""
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+	hostname := func() (string, error) { return "laptop", nil }
+	gitUserName := func() (string, error) { return "alice", nil }
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Hostname: hostname, DeviceFromGit: true, GitUserName: gitUserName, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.Equal(t, "alice@laptop", readProfilesFile(t, c).GetString("tests.device_name"))
+}
+
+func TestLoginWithAPIKeyDeviceFromGitUnavailable(t *testing.T) {
+	t.Setenv("STRIPE_DEVICE_NAME", "")
+
+	c := setupAPIKeyLoginConfig(t)
+	c.Profile.DeviceName = ""
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+	hostname := func() (string, error) { return "laptop", nil }
+	gitUserName := func() (string, error) { return "", errors.New("git not found") }
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Hostname: hostname, DeviceFromGit: true, GitUserName: gitUserName, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.Equal(t, "laptop", readProfilesFile(t, c).GetString("tests.device_name"))
+}
+
+func TestLoginWithAPIKeyDeviceNameEnv(t *testing.T) {
+	t.Setenv("STRIPE_DEVICE_NAME", "device-from-env")
+
+	c := setupAPIKeyLoginConfig(t)
+	c.Profile.DeviceName = ""
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+	hostname := func() (string, error) { return "", errors.New("hostname must not be resolved") }
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Hostname: hostname, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.Equal(t, "device-from-env", readProfilesFile(t, c).GetString("tests.device_name"))
+}
+
+func TestLoginWithAPIKeyVerifyFails(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	fetcher := &HTTPAccountFetcher{BaseURL: ts.URL}
+	out := new(bytes.Buffer)
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Verify: true, Output: out})
+	require.EqualError(t, err, "failed to verify the API key: failed to retrieve the account: 500 Internal Server Error")
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+
+	// Without verification the key is saved and the error only printed
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Output: out})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "> Error verifying the CLI was setup successfully: failed to retrieve the account: 500 Internal Server Error")
+	require.Equal(t, "sk_test_1234567890", readProfilesFile(t, c).GetString("tests.test_mode_api_key"))
+}
+
+func TestLoginWithAPIKeyCanceled(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := LoginWithAPIKey(ctx, c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: &fakeAccountFetcher{err: ctx.Err()}, Output: new(bytes.Buffer)})
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyModeMismatch(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_live_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Mode: "test"})
+	require.EqualError(t, err, "the API key is a live mode key, but test mode was requested")
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Mode: "live"})
+	require.EqualError(t, err, "the API key is a test mode key, but live mode was requested")
+
+	require.Equal(t, 0, fetcher.calls)
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyMode(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Mode: "test", Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.Equal(t, "sk_test_1234567890", readProfilesFile(t, c).GetString("tests.test_mode_api_key"))
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_live_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Mode: "live", Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	item, err := config.KeyRing.Get("tests.live_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "sk_live_1234567890", string(item.Data))
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "sk_live_******7890", v.GetString("tests.live_mode_api_key"))
+	require.Equal(t, "sk_test_1234567890", v.GetString("tests.test_mode_api_key"))
+}
+
+func TestLoginWithAPIKeyTestKeepsLiveKey(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_live_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Mode: "live", Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_1234567890", APIKeyLoginOptions{Fetcher: fetcher, Mode: "test", Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	item, err := config.KeyRing.Get("tests.live_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "sk_live_1234567890", string(item.Data))
+
+	v := readProfilesFile(t, c)
+	require.Equal(t, "sk_live_******7890", v.GetString("tests.live_mode_api_key"))
+	require.NotEmpty(t, v.GetString("tests.live_mode_key_expires_at"))
+	require.Equal(t, "sk_test_1234567890", v.GetString("tests.test_mode_api_key"))
+}
+
+func TestLoginWithAPIKeyCanceledMidRequest(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	requested := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	go func() {
+		<-requested
+		cancel()
+	}()
+
+	err := LoginWithAPIKey(ctx, c, "sk_test_1234567890", APIKeyLoginOptions{
+		Fetcher: &HTTPAccountFetcher{BaseURL: ts.URL},
+		Output:  new(bytes.Buffer),
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, "login canceled")
+
+	_, statErr := os.Stat(c.ProfilesFile)
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestLoginWithAPIKeyReplaceLiveKey(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "rk_live_1111111111aaaa", APIKeyLoginOptions{Fetcher: fetcher, Force: true, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	// A failed verification keeps the old key
+	fetcher.err = errors.New("invalid API key")
+	err = LoginWithAPIKey(context.Background(), c, "rk_live_2222222222bbbb", APIKeyLoginOptions{Fetcher: fetcher, ReplaceLiveKey: true, Output: new(bytes.Buffer)})
+	require.EqualError(t, err, "failed to verify the API key: invalid API key")
+
+	item, err := config.KeyRing.Get("tests.live_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_1111111111aaaa", string(item.Data))
+
+	fetcher.err = nil
+	err = LoginWithAPIKey(context.Background(), c, "rk_live_2222222222bbbb", APIKeyLoginOptions{Fetcher: fetcher, ReplaceLiveKey: true, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	item, err = config.KeyRing.Get("tests.live_mode_api_key")
+	require.NoError(t, err)
+	require.Equal(t, "rk_live_2222222222bbbb", string(item.Data))
+	require.Equal(t, "rk_live_**********bbbb", readProfilesFile(t, c).GetString("tests.live_mode_api_key"))
+}
+
+func TestLoginWithAPIKeyReplaceLiveKeyNothingStored(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "rk_live_2222222222bbbb", APIKeyLoginOptions{Fetcher: fetcher, ReplaceLiveKey: true, Output: new(bytes.Buffer)})
+	require.EqualError(t, err, "the tests project has no live mode key stored in the keyring to replace")
+
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_2222222222bbbb", APIKeyLoginOptions{Fetcher: fetcher, ReplaceLiveKey: true, Output: new(bytes.Buffer)})
+	require.EqualError(t, err, "only live mode keys can replace the live mode key stored in the keyring")
+	require.Equal(t, 0, fetcher.calls)
+}
+
+func TestLoginWithAPIKeyQuoted(t *testing.T) {
+	for _, quoted := range []string{`"sk_test_1234567890abcd"`, `'sk_test_1234567890abcd'`, ` "sk_test_1234567890abcd" `} {
+		c := setupAPIKeyLoginConfig(t)
+		fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+		err := LoginWithAPIKey(context.Background(), c, quoted, APIKeyLoginOptions{Fetcher: fetcher, Output: new(bytes.Buffer)})
+		require.NoError(t, err, quoted)
+		require.Equal(t, "sk_test_1234567890abcd", readProfilesFile(t, c).GetString("tests.test_mode_api_key"))
+	}
+}
+
+func TestTrimQuotes(t *testing.T) {
+	require.Equal(t, "sk_test_123", trimQuotes(`"sk_test_123"`))
+	require.Equal(t, "sk_test_123", trimQuotes(`'sk_test_123'`))
+	require.Equal(t, `"sk_test_123'`, trimQuotes(`"sk_test_123'`))
+	require.Equal(t, `"sk_test_123`, trimQuotes(`"sk_test_123`))
+	require.Equal(t, `"`, trimQuotes(`"`))
+}
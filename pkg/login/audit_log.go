@@ -0,0 +1,80 @@
+package login
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stripe/stripe-cli/pkg/config"
+)
+
+// AuditLogFileName is the name of the login audit log, stored next to the
+// profiles file unless APIKeyLoginOptions.AuditLog is set
+const AuditLogFileName = "audit.log"
+
+// AuditEvent is a login recorded in the audit log, one JSON object per line.
+// The API key is always redacted.
+type AuditEvent struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Project    string    `json:"project"`
+	Mode       string    `json:"mode"`
+	AccountID  string    `json:"account_id,omitempty"`
+	DeviceName string    `json:"device_name,omitempty"`
+	APIKey     string    `json:"api_key"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditLogPath returns the path of the audit log, defaulting to the config
+// folder of the profiles file
+func auditLogPath(cfg *config.Config, opts APIKeyLoginOptions) string {
+	if opts.AuditLog != "" {
+		return opts.AuditLog
+	}
+
+	return filepath.Join(filepath.Dir(cfg.ProfilesFile), AuditLogFileName)
+}
+
+// recordLogin appends the login of apiKey to the audit log. loginErr is the
+// error the login failed with, if any.
+func recordLogin(cfg *config.Config, opts APIKeyLoginOptions, apiKey, mode string, loginErr error) error {
+	event := AuditEvent{
+		Timestamp:  time.Now().UTC(),
+		Project:    cfg.Profile.ProfileName,
+		Mode:       mode,
+		AccountID:  cfg.Profile.AccountID,
+		DeviceName: cfg.Profile.DeviceName,
+		APIKey:     config.RedactAPIKey(apiKey),
+		Success:    loginErr == nil,
+	}
+
+	if loginErr != nil {
+		event.Error = loginErr.Error()
+	}
+
+	return appendAuditEvent(auditLogPath(cfg, opts), event)
+}
+
+// appendAuditEvent appends event as a JSON line to the audit log at path,
+// creating it only readable by its owner
+func appendAuditEvent(path string, event AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), os.FileMode(0700))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,89 @@
+package login
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/login/acct"
+)
+
+func readAuditEvents(t *testing.T, path string) []AuditEvent {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var events []AuditEvent
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var event AuditEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestLoginWithAPIKeyAuditLog(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	c.Profile.DeviceName = "st-testing"
+	fetcher := &fakeAccountFetcher{account: &acct.Account{ID: "acct_123"}}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890abcd", APIKeyLoginOptions{Fetcher: fetcher, Output: new(bytes.Buffer)})
+	require.NoError(t, err)
+
+	auditLog := filepath.Join(filepath.Dir(c.ProfilesFile), AuditLogFileName)
+	content, err := os.ReadFile(auditLog)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "sk_test_1234567890abcd")
+
+	events := readAuditEvents(t, auditLog)
+	require.Len(t, events, 1)
+	require.False(t, events[0].Timestamp.IsZero())
+	require.Equal(t, AuditEvent{
+		Timestamp:  events[0].Timestamp,
+		Project:    "tests",
+		Mode:       "test",
+		AccountID:  "acct_123",
+		DeviceName: "st-testing",
+		APIKey:     "sk_test_**********abcd",
+		Success:    true,
+	}, events[0])
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(auditLog)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestLoginWithAPIKeyAuditFailures(t *testing.T) {
+	c := setupAPIKeyLoginConfig(t)
+	auditLog := filepath.Join(t.TempDir(), "logs", "audit.log")
+	fetcher := &fakeAccountFetcher{err: os.ErrDeadlineExceeded}
+
+	opts := APIKeyLoginOptions{Fetcher: fetcher, Output: new(bytes.Buffer), Verify: true, AuditLog: auditLog}
+
+	err := LoginWithAPIKey(context.Background(), c, "sk_test_1234567890abcd", opts)
+	require.Error(t, err)
+
+	_, statErr := os.Stat(auditLog)
+	require.True(t, os.IsNotExist(statErr))
+
+	opts.AuditFailures = true
+	err = LoginWithAPIKey(context.Background(), c, "sk_test_1234567890abcd", opts)
+	require.Error(t, err)
+
+	events := readAuditEvents(t, auditLog)
+	require.Len(t, events, 1)
+	require.False(t, events[0].Success)
+	require.Equal(t, "sk_test_**********abcd", events[0].APIKey)
+	require.Equal(t, err.Error(), events[0].Error)
+}
@@ -15,56 +15,19 @@ import (
 
 	"github.com/stripe/stripe-cli/pkg/ansi"
 	"github.com/stripe/stripe-cli/pkg/config"
-	"github.com/stripe/stripe-cli/pkg/login/acct"
-	"github.com/stripe/stripe-cli/pkg/stripe"
 	"github.com/stripe/stripe-cli/pkg/validators"
 )
 
 // InteractiveLogin lets the user set configuration on the command line
-func InteractiveLogin(ctx context.Context, config *config.Config) error {
+func InteractiveLogin(ctx context.Context, config *config.Config, opts APIKeyLoginOptions) error {
 	apiKey, err := getConfigureAPIKey(os.Stdin)
 	if err != nil {
 		return err
 	}
 
 	config.Profile.DeviceName = getConfigureDeviceName(os.Stdin)
-	config.Profile.TestModeAPIKey = apiKey
-	displayName, _ := getDisplayName(ctx, nil, stripe.DefaultAPIBaseURL, apiKey)
 
-	config.Profile.DisplayName = displayName
-
-	profileErr := config.Profile.CreateProfile()
-	if profileErr != nil {
-		return profileErr
-	}
-
-	// The '>' character is automatically included at the end of client login
-	// due to ansi spinner. Since no spinner is used with interactive login,
-	// we need to include it manually to maintain consistency in outputs.
-	message, err := SuccessMessage(ctx, nil, stripe.DefaultAPIBaseURL, apiKey)
-	if err != nil {
-		fmt.Printf("> Error verifying the CLI was setup successfully: %s\n", err)
-	} else {
-		fmt.Printf("> %s\n", message)
-	}
-
-	return nil
-}
-
-// getDisplayName returns the display name for a successfully authenticated user
-func getDisplayName(ctx context.Context, account *acct.Account, baseURL string, apiKey string) (string, error) {
-	// Account will be nil if user did interactive login
-	if account == nil {
-		acc, err := acct.GetUserAccount(ctx, baseURL, apiKey)
-		if err != nil {
-			return "", err
-		}
-
-		account = acc
-	}
-	displayName := account.Settings.Dashboard.DisplayName
-
-	return displayName, nil
+	return LoginWithAPIKey(ctx, config, apiKey, opts)
 }
 
 func getConfigureAPIKey(input io.Reader) (string, error) {
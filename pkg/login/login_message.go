@@ -23,7 +23,7 @@ func SuccessMessage(ctx context.Context, account *acct.Account, baseURL string,
 
 	color := ansi.Color(os.Stdout)
 
-	displayName := account.Settings.Dashboard.DisplayName
+	displayName := account.DisplayName()
 	accountID := account.ID
 
 	if displayName != "" && accountID != "" {
@@ -34,6 +34,32 @@ func Logout(config *config.Config) error {
 	return nil
 }
 
+// Mode function is used to clear the credentials of a single mode for the
+// current Profile, keeping the rest of the profile
+func Mode(config *config.Config, livemode bool) error {
+	mode := "test"
+	if livemode {
+		mode = "live"
+	}
+
+	key, _ := config.Profile.GetAPIKey(livemode)
+	if key == "" {
+		fmt.Printf("You are already logged out of %s mode.\n", mode)
+		return nil
+	}
+
+	fmt.Println("Logging out...")
+
+	err := config.Profile.Clear(&livemode)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Credentials have been cleared for %s mode of %s.\n", mode, config.Profile.ProfileName)
+
+	return nil
+}
+
 // All function is used to clear the credentials on all profiles
 func All(cfg *config.Config) error {
 	fmt.Println("Logging out...")
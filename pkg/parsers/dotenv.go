@@ -0,0 +1,59 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// dotEnvKeyPattern matches the key of a `KEY=value` or `export KEY: value`
+// line, only when it's a valid variable name
+var dotEnvKeyPattern = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*[=:]`)
+
+// ParseDotEnv parses the content of a .env file like godotenv.Parse. Errors
+// report the 1-based line of the malformed entry and its key, but never the
+// content of the line, as godotenv does, since values are often secrets.
+func ParseDotEnv(r io.Reader) (map[string]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := godotenv.Unmarshal(string(content))
+	if err == nil {
+		return env, nil
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	line := malformedDotEnvLine(lines)
+
+	if match := dotEnvKeyPattern.FindStringSubmatch(lines[line-1]); match != nil {
+		return nil, fmt.Errorf("invalid syntax on line %d (key %s)", line, match[1])
+	}
+
+	return nil, fmt.Errorf("invalid syntax on line %d", line)
+}
+
+// malformedDotEnvLine returns the 1-based line after the longest run of lines
+// that parses, which is where the entry that can't be parsed starts. Values
+// quoted over several lines only parse once their closing quote is reached,
+// so they aren't mistaken for malformed entries.
+func malformedDotEnvLine(lines []string) int {
+	valid := 0
+
+	for i := range lines {
+		_, err := godotenv.Unmarshal(strings.Join(lines[:i+1], ""))
+		if err == nil {
+			valid = i + 1
+		}
+	}
+
+	if valid == len(lines) {
+		return len(lines)
+	}
+
+	return valid + 1
+}
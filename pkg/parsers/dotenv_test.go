@@ -0,0 +1,32 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	env, err := ParseDotEnv(strings.NewReader("# comment\nexport A=1\nB=\"multi\nline\"\nC: 3\n"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"A": "1", "B": "multi\nline", "C": "3"}, env)
+}
+
+func TestParseDotEnvMalformed(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"A=1\nB=2\nSTRIPE_FOO=\"sk_test_1234567890\nC=3\n", "invalid syntax on line 3 (key STRIPE_FOO)"},
+		{"A=\"multi\nline\"\nB=2\nsk_test_1234567890 sk_test_0987654321\n", "invalid syntax on line 4"},
+		{"A=1\nSTRIPE-FOO=sk_test_1234567890\n", "invalid syntax on line 2"},
+		{"export STRIPE_FOO='sk_test_1234567890", "invalid syntax on line 1 (key STRIPE_FOO)"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseDotEnv(strings.NewReader(tt.content))
+		require.EqualError(t, err, tt.expected, tt.content)
+		require.NotContains(t, err.Error(), "sk_test")
+	}
+}
@@ -217,6 +217,7 @@ func (rb *Base) MakeMultiPartRequest(ctx context.Context, apiKey, path string, p
 		BaseURL: parsedBaseURL,
 		APIKey:  apiKey,
 		Verbose: rb.showHeaders,
+		Timeout: stripe.DefaultTimeout,
 	}
 
 	return rb.performRequest(ctx, client, path, params, reqBody.String(), errOnStatus, configure)
@@ -233,6 +234,7 @@ func (rb *Base) MakeRequest(ctx context.Context, apiKey, path string, params *Re
 		BaseURL: parsedBaseURL,
 		APIKey:  apiKey,
 		Verbose: rb.showHeaders,
+		Timeout: stripe.DefaultTimeout,
 	}
 
 	return rb.MakeRequestWithClient(ctx, client, path, params, additionalParams, errOnStatus, additionalConfigure)
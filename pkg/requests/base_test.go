@@ -10,8 +10,11 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/stripe/stripe-cli/pkg/stripe"
 )
 
 func TestBuildDataForRequest(t *testing.T) {
@@ -195,6 +198,25 @@ func TestMakeRequest_ErrOnStatus(t *testing.T) {
 	require.Equal(t, "Request failed, status=500, body=:(", err.Error())
 }
 
+func TestMakeRequest_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	defaultTimeout := stripe.DefaultTimeout
+	stripe.DefaultTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { stripe.DefaultTimeout = defaultTimeout })
+
+	rb := Base{APIBaseURL: ts.URL}
+	rb.Method = http.MethodGet
+
+	_, err := rb.MakeRequest(context.Background(), "sk_test_1234", "/foo/bar", &RequestParameters{}, make(map[string]interface{}), true, nil)
+	require.ErrorContains(t, err, "Client.Timeout exceeded")
+}
+
 func TestMakeRequest_ErrOnAPIKeyExpired(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusUnauthorized)
@@ -21,6 +21,7 @@ import (
 
 	g "github.com/stripe/stripe-cli/pkg/git"
 	gitpkg "github.com/stripe/stripe-cli/pkg/git"
+	"github.com/stripe/stripe-cli/pkg/parsers"
 	"github.com/stripe/stripe-cli/pkg/stripe"
 	"github.com/stripe/stripe-cli/pkg/stripeauth"
 )
@@ -361,7 +362,7 @@ func (s *SampleManager) WriteDotEnv(ctx context.Context, sampleLocation string)
 			return err
 		}
 
-		dotenv, err := godotenv.Parse(file)
+		dotenv, err := parsers.ParseDotEnv(file)
 		if err != nil {
 			return err
 		}
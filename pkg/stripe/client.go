@@ -26,6 +26,11 @@ const (
 	V2Request     = "v2"
 )
 
+// DefaultTimeout is the time limit for short-lived requests, such as the
+// ones sent by requests.Base or verifying an API key at login, which set it as
+// the Client's Timeout. It's configured with the --timeout flag.
+var DefaultTimeout = 30 * time.Second
+
 // Client is the API client used to sent requests to Stripe.
 type Client struct {
 	// The base URL (protocol + hostname) used for all requests sent by this
@@ -44,6 +49,15 @@ type Client struct {
 	// Defaults to the standard set of relevant for Stripe headers.
 	VerbosePrintableHeaders []string
 
+	// WrapTransport decorates the transport sending the requests, for example
+	// with WithTrace to log them.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	// Timeout limits the time requests take, including reading the response
+	// body. Requests have no time limit when it's zero, as long-lived
+	// requests such as streamed responses must not be cut short.
+	Timeout time.Duration
+
 	// Cached HTTP client, lazily created the first time the Client is used to
 	// send a request.
 	httpClient *http.Client
@@ -97,7 +111,11 @@ func (c *Client) PerformRequest(ctx context.Context, method, path string, params
 	}
 
 	if c.httpClient == nil {
-		c.httpClient = newHTTPClient(c.Verbose, c.VerbosePrintableHeaders, os.Getenv("STRIPE_CLI_UNIX_SOCKET"))
+		c.httpClient = newHTTPClient(c.Verbose, c.VerbosePrintableHeaders, os.Getenv("STRIPE_CLI_UNIX_SOCKET"), c.Timeout)
+
+		if c.WrapTransport != nil {
+			c.httpClient.Transport = c.WrapTransport(c.httpClient.Transport)
+		}
 	}
 
 	if ctx != nil {
@@ -130,7 +148,7 @@ func sendTelemetryEvent(ctx context.Context, requestID string, livemode bool) {
 	}
 }
 
-func newHTTPClient(verbose bool, printableHeaders []string, unixSocket string) *http.Client {
+func newHTTPClient(verbose bool, printableHeaders []string, unixSocket string, timeout time.Duration) *http.Client {
 	var httpTransport http.RoundTripper
 
 	if unixSocket != "" {
@@ -172,6 +190,7 @@ func newHTTPClient(verbose bool, printableHeaders []string, unixSocket string) *
 
 	return &http.Client{
 		Transport: httpTransport,
+		Timeout:   timeout,
 	}
 }
 
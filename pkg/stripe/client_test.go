@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -166,3 +167,27 @@ func TestPerformRequest_ConfigureFuncReturnsError(t *testing.T) {
 		resp.Body.Close()
 	}
 }
+
+func TestPerformRequest_NoDefaultTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	defaultTimeout := DefaultTimeout
+	DefaultTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { DefaultTimeout = defaultTimeout })
+
+	baseURL, _ := url.Parse(ts.URL)
+
+	// long-lived requests are only limited when the client sets a Timeout
+	client := Client{BaseURL: baseURL}
+	resp, err := client.PerformRequest(context.TODO(), http.MethodGet, "/get", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	client = Client{BaseURL: baseURL, Timeout: DefaultTimeout}
+	_, err = client.PerformRequest(context.TODO(), http.MethodGet, "/get", "", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
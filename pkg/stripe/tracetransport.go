@@ -0,0 +1,66 @@
+package stripe
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var credentialsRegexp = regexp.MustCompile("(?i)^(basic|bearer) (.+)")
+
+// TraceTransport logs every request sent through it and the response
+// received at debug level, with all headers but credentials redacted. It is
+// used to troubleshoot requests failing behind proxies and gateways.
+type TraceTransport struct {
+	// Transport sends the requests, defaults to http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// WithTrace wraps transport in a TraceTransport, for use as a Client's
+// WrapTransport
+func WithTrace(transport http.RoundTripper) http.RoundTripper {
+	return &TraceTransport{Transport: transport}
+}
+
+// RoundTrip logs req, sends it and logs the response
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	logger := log.WithFields(log.Fields{
+		"prefix": "stripe.TraceTransport.RoundTrip",
+	})
+
+	logger.Debugf("> %s %s", req.Method, req.URL)
+	traceHeaders(logger, req.Header, ">")
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		logger.Debugf("< %s", err)
+		return nil, err
+	}
+
+	logger.Debugf("< %s", resp.Status)
+	traceHeaders(logger, resp.Header, "<")
+
+	return resp, nil
+}
+
+func traceHeaders(logger *log.Entry, header http.Header, indent string) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, v := range header[name] {
+			logger.Debugf("%s %s: %s", indent, name, credentialsRegexp.ReplaceAllString(v, "$1 [REDACTED]"))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package stripe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Request-Id", "req_123")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	hook := test.NewGlobal()
+	t.Cleanup(hook.Reset)
+
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	t.Cleanup(func() { log.SetLevel(level) })
+
+	baseURL, _ := url.Parse(ts.URL)
+	client := &Client{
+		BaseURL:       baseURL,
+		APIKey:        "sk_test_1234567890abcd",
+		WrapTransport: WithTrace,
+	}
+
+	resp, err := client.PerformRequest(context.Background(), http.MethodGet, "/v1/account", "", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		require.Equal(t, log.DebugLevel, entry.Level)
+		require.NotContains(t, entry.Message, "sk_test_1234567890abcd")
+		messages = append(messages, entry.Message)
+	}
+
+	require.Contains(t, messages, "> GET "+ts.URL+"/v1/account")
+	require.Contains(t, messages, "> Authorization: Bearer [REDACTED]")
+	require.Contains(t, messages, "< 200 OK")
+	require.Contains(t, messages, "< Request-Id: req_123")
+}
+
+func TestTraceTransportKeepsUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported on Windows")
+	}
+
+	socket := filepath.Join(t.TempDir(), "stripe.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.Listener = listener
+	ts.Start()
+	defer ts.Close()
+
+	t.Setenv("STRIPE_CLI_UNIX_SOCKET", socket)
+
+	// the host doesn't resolve, the request only succeeds through the socket
+	baseURL, _ := url.Parse("http://stripe.invalid")
+	client := &Client{
+		BaseURL:       baseURL,
+		WrapTransport: WithTrace,
+	}
+
+	resp, err := client.PerformRequest(context.Background(), http.MethodGet, "/v1/account", "", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
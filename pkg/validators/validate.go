@@ -52,22 +52,61 @@ func CallNonEmpty(validator ArgValidator, value string) error {
 
 // APIKey validates that a string looks like an API key.
 func APIKey(input string) error {
+	keyParts, err := splitAPIKey(input)
+	if err != nil {
+		return err
+	}
+
+	if keyParts[0] != "sk" && keyParts[0] != "rk" {
+		return errors.New("the CLI only supports using a secret or restricted key")
+	}
+
+	return nil
+}
+
+// ParseAPIKey classifies an API key, returning its mode ("test" or "live") and
+// type ("secret", "restricted" or "publishable"). Unlike APIKey, it accepts
+// publishable keys so that they can be reported as such.
+func ParseAPIKey(input string) (mode string, keyType string, err error) {
+	keyParts, err := splitAPIKey(input)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch keyParts[0] {
+	case "sk":
+		keyType = "secret"
+	case "rk":
+		keyType = "restricted"
+	case "pk":
+		keyType = "publishable"
+	default:
+		return "", "", fmt.Errorf("%s_ is not a recognized API key prefix (sk_, rk_, pk_)", keyParts[0])
+	}
+
+	mode = keyParts[1]
+	if mode != "test" && mode != "live" {
+		return "", "", fmt.Errorf("%s is not a recognized API key mode (test, live)", mode)
+	}
+
+	return mode, keyType, nil
+}
+
+// splitAPIKey checks the length and shape shared by all API keys and returns
+// their underscore separated parts
+func splitAPIKey(input string) ([]string, error) {
 	if len(input) == 0 {
-		return ErrAPIKeyNotConfigured
+		return nil, ErrAPIKeyNotConfigured
 	} else if len(input) < 12 {
-		return errors.New("the API key provided is too short, it must be at least 12 characters long")
+		return nil, errors.New("the API key provided is too short, it must be at least 12 characters long")
 	}
 
 	keyParts := strings.Split(input, "_")
 	if len(keyParts) < 3 {
-		return errors.New("you are using a legacy-style API key which is unsupported by the CLI. Please generate a new test mode API key")
-	}
-
-	if keyParts[0] != "sk" && keyParts[0] != "rk" {
-		return errors.New("the CLI only supports using a secret or restricted key")
+		return nil, errors.New("you are using a legacy-style API key which is unsupported by the CLI. Please generate a new test mode API key")
 	}
 
-	return nil
+	return keyParts, nil
 }
 
 // APIKeyNotRestricted validates that a string looks like a secret API key and is not a restricted key.
@@ -101,6 +140,15 @@ func Account(account string) error {
 	return fmt.Errorf("%s is not an acceptable account filter (CONNECT_IN, CONNECT_OUT, SELF)", account)
 }
 
+// AccountID validates that a string looks like a Stripe account ID.
+func AccountID(id string) error {
+	if !strings.HasPrefix(id, "acct_") || len(id) == len("acct_") {
+		return fmt.Errorf("%s is not an account ID, it should start with acct_", id)
+	}
+
+	return nil
+}
+
 // HTTPMethod validates that a string is an acceptable HTTP method.
 func HTTPMethod(method string) error {
 	methodUpper := strings.ToUpper(method)
@@ -42,6 +42,64 @@ func TestTestmodeRestrictedAPIKey(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestParseAPIKeySecretTest(t *testing.T) {
+	mode, keyType, err := ParseAPIKey("sk_test_12345")
+	require.NoError(t, err)
+	require.Equal(t, "test", mode)
+	require.Equal(t, "secret", keyType)
+}
+
+func TestParseAPIKeyRestrictedLive(t *testing.T) {
+	mode, keyType, err := ParseAPIKey("rk_live_12345")
+	require.NoError(t, err)
+	require.Equal(t, "live", mode)
+	require.Equal(t, "restricted", keyType)
+}
+
+func TestParseAPIKeyPublishableTest(t *testing.T) {
+	mode, keyType, err := ParseAPIKey("pk_test_12345")
+	require.NoError(t, err)
+	require.Equal(t, "test", mode)
+	require.Equal(t, "publishable", keyType)
+}
+
+func TestParseAPIKeyMalformed(t *testing.T) {
+	_, _, err := ParseAPIKey("")
+	require.Equal(t, ErrAPIKeyNotConfigured, err)
+
+	_, _, err = ParseAPIKey("sk_test")
+	require.EqualError(t, err, "the API key provided is too short, it must be at least 12 characters long")
+
+	_, _, err = ParseAPIKey("sk_123457890abcdef")
+	require.EqualError(t, err, "you are using a legacy-style API key which is unsupported by the CLI. Please generate a new test mode API key")
+
+	_, _, err = ParseAPIKey("xk_test_12345")
+	require.EqualError(t, err, "xk_ is not a recognized API key prefix (sk_, rk_, pk_)")
+
+	_, _, err = ParseAPIKey("sk_prod_12345")
+	require.EqualError(t, err, "prod is not a recognized API key mode (test, live)")
+}
+
+func TestParseAPIKeyMatchesAPIKey(t *testing.T) {
+	for _, input := range []string{"", "sk_test", "sk_123457890abcdef", "sk_test_12345", "rk_live_12345"} {
+		_, _, parseErr := ParseAPIKey(input)
+		require.Equal(t, APIKey(input), parseErr, input)
+	}
+}
+
+func TestAccountID(t *testing.T) {
+	err := AccountID("acct_123")
+	require.NoError(t, err)
+}
+
+func TestAccountIDInvalid(t *testing.T) {
+	err := AccountID("cus_123")
+	require.Equal(t, "cus_123 is not an account ID, it should start with acct_", fmt.Sprintf("%s", err))
+
+	err = AccountID("acct_")
+	require.Error(t, err)
+}
+
 func TestHTTPMethod(t *testing.T) {
 	err := HTTPMethod("GET")
 	require.NoError(t, err)